@@ -1,7 +1,6 @@
 package main
 
 import (
-	"EnigmaLorenz/pkg/util"
 	"errors"
 	"flag"
 	"fmt"
@@ -9,61 +8,43 @@ import (
 	"strconv"
 	"strings"
 )
-import "EnigmaLorenz/pkg/enigma"
+import (
+	"EnigmaLorenz/pkg/convention"
+	"EnigmaLorenz/pkg/enigma"
+)
 
-// validateRotorInput takes the user's rotor parameter and returns the corresponding Rotor.
+// validateRotorInput takes the user's rotor parameter and returns the corresponding Rotor from the registry.
 // An error is returned in cases where the input is not valid.
 //
 // Errors
 //
 // The returned error will not be nil if:
 //	- There are not 3 arguments seperated by a space
-//	- The first argument is not one of the valid rotors (I - VIII|beta|gamma|)
-//	- The rotor setting is not between 1 and 26 inclusively
-//	- The ring setting is not between 0 and 25 exclusively
+//	- The first argument is not one of the registered rotors (I - VIII|beta|gamma|Z30-I - Z30-III| or from a catalog)
+//	- The rotor setting is not between 1 and 26 inclusively (or the size of a catalog rotor)
+//	- The ring setting is not between 0 and 25 exclusively (or one less than the size of a catalog rotor)
 //
-func validateRotorInput(input string) (enigma.Rotor, error) {
+func validateRotorInput(input string, registry *enigma.Registry) (enigma.Rotor, error) {
 	args := strings.Split(input, " ")
-	rotorSet := enigma.GenerateRotors()
-	rotor := rotorSet.I
 
 	if len(args) != 3 {
-		return rotor, errors.New("incorrect number of arguments")
+		return enigma.Rotor{}, errors.New("incorrect number of arguments")
 	}
 
 	// Validate rotor wheel
-	switch args[0] {
-	case "I":
-		rotor = rotorSet.I
-	case "II":
-		rotor = rotorSet.II
-	case "III":
-		rotor = rotorSet.III
-	case "IV":
-		rotor = rotorSet.IV
-	case "V":
-		rotor = rotorSet.V
-	case "VI":
-		rotor = rotorSet.VI
-	case "VII":
-		rotor = rotorSet.VII
-	case "VIII":
-		rotor = rotorSet.VIII
-	case "beta":
-		rotor = rotorSet.Beta
-	case "gamma":
-		rotor = rotorSet.Gamma
-	default:
-		return rotor, errors.New("rotor selection is invalid")
+	rotor, err := registry.Rotor(args[0])
+	if err != nil {
+		return rotor, err
 	}
+	size := len(rotor.Wires)
 
 	// Validate rotor position
 	pos, err := strconv.Atoi(args[1])
 	if err != nil {
 		return rotor, err
 	}
-	if pos < 1 || pos > 26 {
-		return rotor, errors.New("rotor position not between 1 and 26")
+	if pos < 1 || pos > size {
+		return rotor, fmt.Errorf("rotor position not between 1 and %d", size)
 	}
 
 	rotor.SetShownPos(byte(pos))
@@ -73,8 +54,8 @@ func validateRotorInput(input string) (enigma.Rotor, error) {
 	if err != nil {
 		return rotor, err
 	}
-	if ring < 0 || ring > 25 {
-		return rotor, errors.New("ring setting not between 0 and 25")
+	if ring < 0 || ring > size-1 {
+		return rotor, fmt.Errorf("ring setting not between 0 and %d", size-1)
 	}
 
 	rotor.SetRingSetting(byte(ring))
@@ -88,38 +69,120 @@ func validateRotorInput(input string) (enigma.Rotor, error) {
 //
 // Errors
 //
-// The returned error will not be nil if the rotor is not one of the specified reflectors (A, B, C, b, c)
-func validateReflectorInput(input string) (enigma.Rotor, error) {
-	rotorSet := enigma.GenerateRotors()
-	switch input {
-	case "A":
-		return rotorSet.UKW_A, nil
-	case "B":
-		return rotorSet.UKW_B, nil
-	case "C":
-		return rotorSet.UKW_C, nil
-	case "b":
-		return rotorSet.UKW_b, nil
-	case "c":
-		return rotorSet.UKW_c, nil
-	default:
-		return rotorSet.UKW_B, errors.New("incorrect value for reflector")
+// The returned error will not be nil if the reflector is not one of the registered reflectors (A, B, C, b, c, Z30-UKW or from a catalog)
+func validateReflectorInput(input string, registry *enigma.Registry) (enigma.Rotor, error) {
+	return registry.Reflector(input)
+}
+
+// validateModelInput takes the user's model parameter and returns the Models the machine may be set up on,
+// one of which it must be possible on.
+// With no model given the Model is worked out from the setup: the Z30 if the rotors are lettered with digits,
+// the M4 if a fourth rotor is used, and otherwise either the Enigma I or the M3, so that reflector A may be used.
+// The model "any" skips the check, allowing rotors from a catalog and machines that were never built, and nil is returned for it.
+//
+// Errors
+//
+// The returned error will not be nil if the model is not one of I, M3, M4, Z30 or any.
+func validateModelInput(input string, useFourthRotor bool, alphabet enigma.Alphabet) ([]enigma.Model, error) {
+	switch {
+	case input == "any":
+		return nil, nil
+	case input == "" && alphabet.Symbols() == enigma.DigitAlphabet().Symbols():
+		return []enigma.Model{enigma.EnigmaZ30()}, nil
+	case input == "" && useFourthRotor:
+		return []enigma.Model{enigma.EnigmaM4()}, nil
+	case input == "":
+		return []enigma.Model{enigma.EnigmaM3(), enigma.EnigmaI()}, nil
+	}
+	model, err := enigma.NewModel(input)
+	if err != nil {
+		return nil, err
+	}
+	return []enigma.Model{model}, nil
+}
+
+// checkModels returns no problems if the machine can be set up on any of the models.
+// Otherwise the problems with the model needing the fewest changes are returned, preferring the earlier model on a tie.
+func checkModels(models []enigma.Model, machine enigma.Enigma, useFourthRotor bool) []enigma.ConfigProblem {
+	var closest []enigma.ConfigProblem
+	for idx, model := range models {
+		problems := model.Check(machine, useFourthRotor)
+		if len(problems) == 0 {
+			return problems
+		}
+		if idx == 0 || len(problems) < len(closest) {
+			closest = problems
+		}
+	}
+	return closest
+}
+
+// formatOutput sets out the machine's output in the chosen format.
+// The plain format returns the output unchanged, and the other formats are the layouts of enigma.ParseLayout.
+//
+// Errors
+//
+// The returned error will not be nil if the format is unknown or a header detail needed by the layout is missing or invalid.
+func formatOutput(format string, output string, wrap int, header enigma.Message) (string, error) {
+	if format == "plain" {
+		return output, nil
+	}
+	layout, err := enigma.ParseLayout(format)
+	if err != nil {
+		return "", errors.New("format must be plain, groups, heer or kriegsmarine")
+	}
+	header.Text = output
+	return header.Format(layout, wrap)
+}
+
+// validateConventionInput takes the user's convention parameters and returns the plaintext convention to use, if any.
+// The returned bool is false when no convention is given, in which case the message is only capitalized and has its spaces removed.
+//
+// Errors
+//
+// The returned error will not be nil if the convention is unknown, restore is set without a convention,
+// or restore is used with a format other than plain, as restored text is not letters alone.
+func validateConventionInput(input string, restore bool, format string) (convention.Convention, bool, error) {
+	if input == "" {
+		if restore {
+			return convention.Heer, false, errors.New("a convention must be given to restore the output from")
+		}
+		return convention.Heer, false, nil
+	}
+	if restore && format != "plain" {
+		return convention.Heer, false, errors.New("restored output can only use the plain format")
+	}
+	chosen, err := convention.ParseConvention(input)
+	return chosen, err == nil, err
+}
+
+// readRegistry returns the registry of the standard rotors, with the rotors and reflectors of a catalog file added if a path is given.
+func readRegistry(path string) (*enigma.Registry, error) {
+	registry := enigma.StandardRegistry()
+	if path == "" {
+		return registry, nil
 	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return registry, err
+	}
+	return registry, registry.Load(string(text))
 }
 
-// validatePlugboardInput takes the user's plugboard parameter and returns the corresponding Plugboard.
+// validatePlugboardInput takes the user's plugboard parameter and returns the corresponding Plugboard for the rotors' alphabet.
 // an error is returned in cases where the parameter is not valid
 //
 // Errors
 //
 // The returned error will not be nil if:
 //	- There are not a character either side of the mapping
-//	- The character is not between A-Z
+//	- The character is not in the alphabet
 // 	- There is more than one character either side of the mapping
+//	- A character is plugged to itself
 //
-func validatePlugboardInput(input string) (enigma.Plugboard, error) {
+func validatePlugboardInput(input string, alphabet enigma.Alphabet) (enigma.Plugboard, error) {
 
-	plugboard := enigma.NewPlugboard()
+	plugboard := enigma.NewPlugboardFor(alphabet)
 	if len(input) == 0 {
 		return plugboard, nil
 	}
@@ -132,7 +195,7 @@ func validatePlugboardInput(input string) (enigma.Plugboard, error) {
 			return plugboard, errors.New("incorrect format for plugboard")
 		}
 
-		if !util.ValidChars(strings.ToUpper(split[0]), false) || !util.ValidChars(strings.ToUpper(split[1]), false) {
+		if !alphabet.Valid(strings.ToUpper(split[0])) || !alphabet.Valid(strings.ToUpper(split[1])) {
 			return plugboard, errors.New("incorrect characters passed to plugboard")
 		}
 
@@ -143,40 +206,58 @@ func validatePlugboardInput(input string) (enigma.Plugboard, error) {
 		plugboard.AddPlug(strings.ToUpper(split[0])[0], strings.ToUpper(split[1])[0])
 	}
 
-	return plugboard, nil
+	return plugboard, plugboard.Validate()
 }
 
 func main() {
 	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
 
-	leftRotorPtr := flag.String("l", "I 1 0", "Left rotor number (I-VIII), position (1-26), and ring setting (0-25)")
-	centerRotorPtr := flag.String("c", "II 1 0", "Center rotor number (I-VIII), position (1-26), and ring setting (0-25)")
-	rightRotorPtr := flag.String("r", "III 1 0", "Right rotor number (I-VIII), position (1-26), and ring setting (0-25)")
+	leftRotorPtr := flag.String("l", "I 1 0", "Left rotor number (I-VIII, Z30-I-Z30-III or from -catalog), position (1-26), and ring setting (0-25)")
+	centerRotorPtr := flag.String("c", "II 1 0", "Center rotor number (I-VIII, Z30-I-Z30-III or from -catalog), position (1-26), and ring setting (0-25)")
+	rightRotorPtr := flag.String("r", "III 1 0", "Right rotor number (I-VIII, Z30-I-Z30-III or from -catalog), position (1-26), and ring setting (0-25)")
 	fourthRotorPtr := flag.String("f", "", "Fourth rotor (beta|gamma), position (1-26), and ring setting (0-25) [optional]")
-	reflectorPtr := flag.String("ukw", "B", "Reflector to use (A|B|C|a|b)")
+	reflectorPtr := flag.String("ukw", "B", "Reflector to use (A|B|C|b|c|Z30-UKW or from -catalog)")
 	plugsPtr := flag.String("plugs", "", "Plug mappings in the form of 'A:B C:D'[optional], position, and ring setting")
+	catalogPtr := flag.String("catalog", "", "JSON or YAML file of extra rotors and reflectors to choose from by name [optional]")
+	formatPtr := flag.String("format", "plain", "Layout of the output (plain|groups|heer|kriegsmarine), heer and kriegsmarine add a message header")
+	wrapPtr := flag.Int("wrap", 10, "Number of letter groups on each line of formatted output, 0 to not wrap")
+	fromPtr := flag.String("from", "", "Call sign of the sending station for the message header [optional]")
+	toPtr := flag.String("to", "", "Call sign of the receiving station for the heer message header [optional]")
+	timePtr := flag.String("time", "", "Time of origin as four digits for the message header (e.g. 1510) [optional]")
+	indicatorPtr := flag.String("indicator", "", "Indicator groups for the message header, 'EHZ TBS' for heer or one four letter group for kriegsmarine [optional]")
+	kenngruppePtr := flag.String("kenngruppe", "", "Five letter Kenngruppe sent as the first group of a heer message, or when reading an intercept any value to drop its first group [optional]")
+	conventionPtr := flag.String("convention", "", "Write the plaintext in the German military convention (heer|kriegsmarine) before encrypting [optional]")
+	restorePtr := flag.Bool("restore", false, "Restore the decrypted output to readable text from the convention given by -convention")
+	interceptPtr := flag.Bool("intercept", false, "Read the message as an intercept in groups, removing any heer or kriegsmarine header before decrypting")
+	modelPtr := flag.String("model", "", "Enigma model the setup must be possible on (I|M3|M4|Z30|any), Z30 with Z30 rotors, M4 with a fourth rotor and I or M3 otherwise [optional]")
 
 	flag.Parse()
 
-	leftRotor, err := validateRotorInput(*leftRotorPtr)
+	registry, err := readRegistry(*catalogPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for rotor catalog: %s\n", err)
+		os.Exit(1)
+	}
+
+	leftRotor, err := validateRotorInput(*leftRotorPtr, registry)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error for left rotor: %s\n", err)
 		os.Exit(1)
 	}
 
-	centerRotor, err := validateRotorInput(*centerRotorPtr)
+	centerRotor, err := validateRotorInput(*centerRotorPtr, registry)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error for center rotor: %s\n", err)
 		os.Exit(1)
 	}
 
-	rightRotor, err := validateRotorInput(*rightRotorPtr)
+	rightRotor, err := validateRotorInput(*rightRotorPtr, registry)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error for right rotor: %s\n", err)
 		os.Exit(1)
 	}
 
-	fourthRotor, err := validateRotorInput(*fourthRotorPtr)
+	fourthRotor, err := validateRotorInput(*fourthRotorPtr, registry)
 	useFourthRotor := true
 	if err != nil {
 		if *fourthRotorPtr == "" {
@@ -187,13 +268,16 @@ func main() {
 		}
 	}
 
-	reflector, err := validateReflectorInput(*reflectorPtr)
+	reflector, err := validateReflectorInput(*reflectorPtr, registry)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error for reflector: %s\n", err)
 		os.Exit(1)
 	}
 
-	plugs, err := validatePlugboardInput(*plugsPtr)
+	// The keyboard and plugboard are lettered as the rotors are, so a Z30 or catalog rotor set takes its own symbols.
+	alphabet := leftRotor.Alphabet
+
+	plugs, err := validatePlugboardInput(*plugsPtr, alphabet)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error for plugboard: %s\n", err)
 		os.Exit(1)
@@ -206,19 +290,77 @@ func main() {
 		FourthRotor: fourthRotor,
 		Reflector:   reflector,
 		Plugs:       plugs,
+		Alphabet:    alphabet,
+	}
+
+	models, err := validateModelInput(*modelPtr, useFourthRotor, alphabet)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for model: %s\n", err)
+		os.Exit(1)
+	}
+	problems := checkModels(models, machine, useFourthRotor)
+	for _, problem := range problems {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for %s\n", problem.Error())
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	plainConvention, useConvention, err := validateConventionInput(*conventionPtr, *restorePtr, *formatPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for convention: %s\n", err)
+		os.Exit(1)
 	}
 
 	message := strings.Replace(strings.ToUpper(*messagePtr), " ", "", -1)
+	if useConvention && !*restorePtr && !*interceptPtr {
+		message, err = convention.Normalize(*messagePtr, plainConvention)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for message: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if *interceptPtr {
+		intercept, _, err := enigma.ParseMessage(*messagePtr, *kenngruppePtr != "")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for intercept: %s\n", err)
+			os.Exit(1)
+		}
+		message = intercept.Text
+	}
 
-	if !util.ValidChars(message, false) {
-		_, _ = fmt.Fprintf(os.Stderr, "Invalid characters in message: %s", message)
+	if !alphabet.Valid(message) {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid characters in message, must be one of %s: %s\n", alphabet.Symbols(), message)
+		os.Exit(1)
 	}
 
 	cipher, err := machine.Encrypt(message, useFourthRotor)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	if *restorePtr {
+		cipher, err = convention.Restore(cipher, plainConvention)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for restore: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	header := enigma.Message{
+		From:       *fromPtr,
+		To:         *toPtr,
+		Time:       *timePtr,
+		Indicator:  strings.ToUpper(*indicatorPtr),
+		Kenngruppe: strings.ToUpper(*kenngruppePtr),
+	}
+	formatted, err := formatOutput(*formatPtr, cipher, *wrapPtr, header)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for format: %s\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Println(cipher)
+	fmt.Println(formatted)
 
 }
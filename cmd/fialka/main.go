@@ -0,0 +1,118 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/fialka"
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// validateRotorsInput takes the user's rotor order and positions and returns the rotors set to those positions.
+// Rotors are given by number (1-10), with R appended for a core placed reversed.
+//
+// Errors
+//
+// The returned error will not be nil if:
+//	- There are not 10 rotors or 10 positions
+//	- A rotor number is not between 1 and 10, or is used more than once
+//	- A position is not between 1 and 30 inclusively
+//
+func validateRotorsInput(order string, positions string) ([10]enigma.Rotor, error) {
+	var rotors [10]enigma.Rotor
+	names := strings.Fields(order)
+	posFields := strings.Fields(positions)
+	if len(names) != len(rotors) || len(posFields) != len(rotors) {
+		return rotors, errors.New("10 rotors and 10 positions must be given")
+	}
+
+	rotorSet := fialka.GenerateRotors()
+	used := [10]bool{}
+	for idx, name := range names {
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.ToUpper(name), "R"))
+		if err != nil || number < 1 || number > len(rotorSet) {
+			return rotors, fmt.Errorf("rotor %s is not valid", name)
+		}
+		if used[number-1] {
+			return rotors, fmt.Errorf("rotor %d is used more than once", number)
+		}
+		used[number-1] = true
+
+		rotor := rotorSet[number-1]
+		if strings.HasSuffix(strings.ToUpper(name), "R") {
+			rotor = rotor.Reversed()
+		}
+
+		pos, err := strconv.Atoi(posFields[idx])
+		if err != nil || pos < 1 || pos > fialka.Contacts {
+			return rotors, fmt.Errorf("rotor position not between 1 and %d", fialka.Contacts)
+		}
+		rotor.SetShownPos(byte(pos))
+		rotors[idx] = rotor
+	}
+	return rotors, nil
+}
+
+// readCard reads the punched card file, or returns the identity card if no file is given.
+//
+// Errors
+//
+// The returned error will not be nil if the file cannot be read or does not hold a valid card.
+func readCard(path string) (fialka.Card, error) {
+	if path == "" {
+		return fialka.IdentityCard(), nil
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return fialka.Card{}, err
+	}
+	return fialka.ParseCard(string(text))
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+	rotorsPtr := flag.String("rotors", "1 2 3 4 5 6 7 8 9 10", "Rotor order (1-10, with R appended if reversed) from the card to the reflector")
+	positionsPtr := flag.String("pos", "1 1 1 1 1 1 1 1 1 1", "Position of each rotor (1-30)")
+	cardPtr := flag.String("card", "", "File holding the punched card wiring the keyboard to the rotors [optional]")
+	modePtr := flag.String("mode", "latin", fmt.Sprintf("Keyboard mode (%s)", strings.Join(fialka.KeyboardNames(), "|")))
+
+	flag.Parse()
+
+	rotors, err := validateRotorsInput(*rotorsPtr, *positionsPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for rotors: %s\n", err)
+		os.Exit(1)
+	}
+
+	card, err := readCard(*cardPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for card: %s\n", err)
+		os.Exit(1)
+	}
+
+	keyboard, err := fialka.NewKeyboard(*modePtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for keyboard mode: %s\n", err)
+		os.Exit(1)
+	}
+
+	machine := fialka.Fialka{
+		Rotors:    rotors,
+		Reflector: fialka.DefaultReflector(),
+		Card:      card,
+		Keyboard:  keyboard,
+	}
+
+	message := strings.Replace(strings.ToUpper(*messagePtr), " ", "", -1)
+	cipher, err := machine.Encrypt(message)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(util.FormatGroups(cipher, 5, 0))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/hebern"
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+import "EnigmaLorenz/pkg/enigma"
+
+// validateRotorsInput takes the user's rotors and positions and returns the rotors set to those positions.
+// The Hebern rotors are wired as the Enigma rotors I-VIII.
+//
+// Errors
+//
+// The returned error will not be nil if:
+//	- There is not 1 or 5 rotors, or a different number of positions
+//	- A rotor is not one of I-VIII
+//	- A rotor position is not between 1 and 26 inclusively
+//
+func validateRotorsInput(names string, positions string) ([]enigma.Rotor, error) {
+	rotorNames := strings.Fields(names)
+	posFields := strings.Fields(positions)
+	if len(rotorNames) != 1 && len(rotorNames) != 5 {
+		return nil, errors.New("1 or 5 rotors must be given")
+	}
+	if len(posFields) != len(rotorNames) {
+		return nil, errors.New("a position must be given for each rotor")
+	}
+
+	rotorSet := enigma.GenerateRotors()
+	available := map[string]enigma.Rotor{
+		"I": rotorSet.I, "II": rotorSet.II, "III": rotorSet.III, "IV": rotorSet.IV,
+		"V": rotorSet.V, "VI": rotorSet.VI, "VII": rotorSet.VII, "VIII": rotorSet.VIII,
+	}
+
+	rotors := []enigma.Rotor{}
+	for idx, name := range rotorNames {
+		rotor, exists := available[name]
+		if !exists {
+			return nil, errors.New("rotor selection is invalid")
+		}
+		pos, err := strconv.Atoi(posFields[idx])
+		if err != nil {
+			return nil, err
+		}
+		if pos < 1 || pos > 26 {
+			return nil, errors.New("rotor position not between 1 and 26")
+		}
+		rotor.SetShownPos(byte(pos))
+		rotors = append(rotors, rotor)
+	}
+	return rotors, nil
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+	rotorsPtr := flag.String("rotors", "I II III IV V", "1 or 5 rotors (I-VIII) from left to right")
+	positionsPtr := flag.String("pos", "1 1 1 1 1", "Position of each rotor (1-26)")
+	decryptPtr := flag.Bool("d", false, "Whether you are seeking to decrypt a message")
+
+	flag.Parse()
+
+	rotors, err := validateRotorsInput(*rotorsPtr, *positionsPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for rotors: %s\n", err)
+		os.Exit(1)
+	}
+	machine := hebern.Hebern{Rotors: rotors}
+
+	message := strings.Replace(strings.ToUpper(*messagePtr), " ", "", -1)
+
+	if !util.ValidChars(message, false) {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid characters in message: %s\n", message)
+		os.Exit(1)
+	}
+
+	var output string
+	if *decryptPtr {
+		output, err = machine.Decrypt(message)
+	} else {
+		output, err = machine.Encrypt(message)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
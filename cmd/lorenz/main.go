@@ -2,7 +2,8 @@ package main
 
 import (
 	"EnigmaLorenz/pkg/lorenz"
-	"EnigmaLorenz/pkg/util"
+	"EnigmaLorenz/pkg/tape"
+	"EnigmaLorenz/pkg/vernam"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,14 +12,6 @@ import (
 	"strings"
 )
 
-func validateMessage(message string) (string, error) {
-	message = strings.ToUpper(message)
-	if !util.ValidChars(message, true) {
-		return "", errors.New("invalid character found in text")
-	}
-	return message, nil
-}
-
 func validateChiPsiPositions(positions string, wheels [5]lorenz.Wheel) ([5]lorenz.Wheel, error) {
 	splitPos := strings.Split(positions, " ")
 	if len(splitPos) != 5 {
@@ -43,8 +36,7 @@ func validateChiPsiPositions(positions string, wheels [5]lorenz.Wheel) ([5]loren
 
 }
 
-func validateMotorPositions(positions string) ([2]lorenz.Wheel, error) {
-	wheels := lorenz.NewWheelSet().Motor
+func validateMotorPositions(positions string, wheels [2]lorenz.Wheel) ([2]lorenz.Wheel, error) {
 	splitPos := strings.Split(positions, " ")
 	if len(splitPos) != 2 {
 		return wheels, errors.New("invalid number of positions given")
@@ -67,41 +59,362 @@ func validateMotorPositions(positions string) ([2]lorenz.Wheel, error) {
 	return wheels, nil
 }
 
+// validateIndicatorInput takes the user's indicator and returns the wheel starts it gives.
+// A QEP indicator, QEP followed by a number, is looked up in the QEP book file,
+// while any other indicator is decoded as 12 letters using the letter lists file.
+//
+// Errors
+//
+// The returned error will not be nil if the indicator is invalid, or the QEP book or letter lists it needs are not given or cannot be read.
+func validateIndicatorInput(indicator string, qepBookPath string, lettersPath string) (lorenz.Start, error) {
+	if !lorenz.IsQEPIndicator(indicator) {
+		if lettersPath == "" {
+			return lorenz.Start{}, errors.New("letter lists must be given with -letters to use a 12 letter indicator")
+		}
+		text, err := os.ReadFile(lettersPath)
+		if err != nil {
+			return lorenz.Start{}, err
+		}
+		lists, err := lorenz.ParseLetterLists(string(text))
+		if err != nil {
+			return lorenz.Start{}, err
+		}
+		return lists.Lookup(indicator)
+	}
+	if qepBookPath == "" {
+		return lorenz.Start{}, errors.New("a QEP book must be given to use a QEP indicator")
+	}
+	text, err := os.ReadFile(qepBookPath)
+	if err != nil {
+		return lorenz.Start{}, err
+	}
+	book, err := lorenz.ParseQEPBook(string(text))
+	if err != nil {
+		return lorenz.Start{}, err
+	}
+	return book.Lookup(indicator)
+}
+
+// readWheelSet reads the wheel patterns from a file written by lorenz genwheels.
+// If no path is given then the standard patterns are returned.
+func readWheelSet(path string) (lorenz.WheelSet, error) {
+	if path == "" {
+		return lorenz.NewWheelSet(), nil
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return lorenz.WheelSet{}, err
+	}
+	return lorenz.ParseWheelSet(string(text))
+}
+
+// genWheels runs the genwheels command, printing a random set of wheel patterns that follow the pattern rules.
+// With -check it instead reports the rules broken by the patterns in a file, exiting with status 1 if any are,
+// With -letters it prints random letter lists for 12 letter indicators, and with -qep a random QEP book.
+func genWheels(args []string) {
+	flags := flag.NewFlagSet("genwheels", flag.ExitOnError)
+	checkPtr := flags.String("check", "", "File of wheel patterns to check against the pattern rules instead of generating [optional]")
+	lettersPtr := flags.Bool("letters", false, "Print random letter lists for 12 letter indicators instead of wheel patterns")
+	qepPtr := flags.Bool("qep", false, "Print a random QEP book for QEP indicators instead of wheel patterns")
+	_ = flags.Parse(args)
+
+	if *qepPtr {
+		book, err := lorenz.GenerateQEPBook()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error generating QEP book: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(book.Format())
+		return
+	}
+
+	if *lettersPtr {
+		lists, err := lorenz.GenerateLetterLists()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error generating letter lists: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(lists.Format())
+		return
+	}
+
+	if *checkPtr != "" {
+		wheels, err := readWheelSet(*checkPtr)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for wheel patterns: %s\n", err)
+			os.Exit(1)
+		}
+		violations := lorenz.ValidateWheelSet(wheels)
+		for _, violation := range violations {
+			fmt.Println(violation.Error())
+		}
+		if len(violations) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("All wheel patterns follow the rules")
+		return
+	}
+
+	wheels, err := lorenz.GenerateWheelSet()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error generating wheel patterns: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(wheels.Format())
+}
+
+// readTape reads the ITA2 codes from a file containing a punched tape dump.
+func readTape(path string) ([]byte, error) {
+	dump, err := os.ReadFile(path)
+	if err != nil {
+		return []byte{}, err
+	}
+	return tape.Parse(string(dump))
+}
+
+// writeTape writes the ITA2 codes to a file as punched tape.
+// A path ending in .svg is written as an SVG image, any other path as ASCII art.
+func writeTape(path string, codes []byte) error {
+	return os.WriteFile(path, renderTape(path, codes), 0644)
+}
+
+// createTape writes the ITA2 codes to a new file as punched tape, in the same way as writeTape,
+// refusing to overwrite a file that already exists.
+func createTape(path string, codes []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(renderTape(path, codes)); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// renderTape draws the ITA2 codes as punched tape, as an SVG image for a path ending in .svg and as ASCII art otherwise.
+func renderTape(path string, codes []byte) []byte {
+	if strings.HasSuffix(strings.ToLower(path), ".svg") {
+		return []byte(tape.RenderSVG(codes))
+	}
+	return []byte(tape.RenderASCII(codes))
+}
+
+// readVernamKey returns the key tape for the vernam command, and the path of the tape to mark as used once it has encrypted a message.
+// An existing key tape is read from keyPath, warning if it has been used to encrypt before.
+// Otherwise a random key tape the length of the message is generated and punched to genKeyPath,
+// which must not already exist so that a key tape is never overwritten.
+// No path is returned when decrypting, since that does not use the key tape up.
+//
+// Errors
+//
+// The returned error will not be nil if no key tape is given, genKeyPath already exists, or the key tape cannot be read or written.
+func readVernamKey(keyPath string, genKeyPath string, length int, decrypt bool) ([]byte, string, error) {
+	if keyPath != "" {
+		key, err := readTape(keyPath)
+		if err != nil {
+			return []byte{}, "", err
+		}
+		if decrypt {
+			return key, "", nil
+		}
+		if _, err := os.Stat(usedMarker(keyPath)); err == nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: key tape %s has already been used to encrypt, reusing it puts both messages in depth\n", keyPath)
+		}
+		return key, keyPath, nil
+	}
+	if genKeyPath == "" || decrypt {
+		return []byte{}, "", errors.New("a key tape must be given with -key, or generated with -genkey when encrypting")
+	}
+	key, err := vernam.GenerateKey(length)
+	if err != nil {
+		return []byte{}, "", err
+	}
+	if err := createTape(genKeyPath, key); err != nil {
+		return []byte{}, "", err
+	}
+	return key, genKeyPath, nil
+}
+
+// usedMarker returns the path of the marker beside a key tape that records it has been used,
+// since a second message on the same tape would be in depth.
+func usedMarker(keyPath string) string {
+	return keyPath + ".used"
+}
+
+// vernamCommand runs the vernam command, adding a one-time key tape to the message as Vernam's cipher did.
+func vernamCommand(args []string) {
+	flags := flag.NewFlagSet("vernam", flag.ExitOnError)
+	messagePtr := flags.String("m", "", "The message to be encrypted/decrypted")
+	decryptPtr := flags.Bool("d", false, "Whether you are seeking to decrypt a message")
+	keyPtr := flags.String("key", "", "File containing the punched key tape to use [optional]")
+	genKeyPtr := flags.String("genkey", "", "File to punch a new random key tape to, as an SVG image if ending in .svg, otherwise as ASCII art [optional]")
+	alphabetPtr := flags.String("alphabet", "ita2", fmt.Sprintf("Teleprinter alphabet to use (%s)", strings.Join(lorenz.AlphabetNames(), "|")))
+	inPtr := flags.String("in", "text", "Format of the message (text|binary|notation), binary and notation are read as raw ITA2 codes")
+	outPtr := flags.String("out", "", "Format of the output (text|binary|notation), notation for text input and text otherwise if not given")
+	_ = flags.Parse(args)
+
+	alphabet, err := lorenz.NewAlphabet(*alphabetPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for alphabet: %s\n", err)
+		os.Exit(1)
+	}
+
+	encoder := lorenz.NewEncoder(alphabet)
+	encoded, err := lorenz.EncodeFormat(*inPtr, *messagePtr, &encoder)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for message: %s\n", err)
+		os.Exit(1)
+	}
+
+	key, usedPath, err := readVernamKey(*keyPtr, *genKeyPtr, len(encoded), *decryptPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for key tape: %s\n", err)
+		os.Exit(1)
+	}
+
+	encrypted, err := vernam.Encrypt(encoded, key)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for key tape: %s\n", err)
+		os.Exit(1)
+	}
+	// The key tape is only used up once a message has actually been encrypted on it.
+	if usedPath != "" {
+		if err := os.WriteFile(usedMarker(usedPath), []byte{}, 0644); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for key tape: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *outPtr == "" {
+		*outPtr = lorenz.OutputFormat(*inPtr)
+	}
+	// Text written out from text keeps its shift codes as stand-ins, so that it can be read back in.
+	decoder := lorenz.NewDecoder(alphabet)
+	decoder.ShowShifts = *inPtr == "text" && *outPtr == "text"
+	decoded, err := lorenz.DecodeFormat(*outPtr, encrypted, &decoder)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for output: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n", decoded)
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "genwheels" {
+		genWheels(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "vernam" {
+		vernamCommand(os.Args[2:])
+		return
+	}
+
 	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
 	chiPositionsPtr := flag.String("chi", "0 0 0 0 0", "The rotor setting for the Chi wheels (0-max)")
 	mPositionsPtr := flag.String("mot", "0 0", "The rotor setting for the Motor wheels (0-max)")
 	psiPositionsPtr := flag.String("psi", "0 0 0 0 0", "The rotor setting for the Psi wheels")
-	decryptPtr := flag.Bool("d", false, "Whether you are seeking to decrypt a message (0-max)")
+	decryptPtr := flag.Bool("d", false, "Deprecated and ignored, as the machine decrypts by encrypting again and -in and -out give the message formats")
+	alphabetPtr := flag.String("alphabet", "ita2", fmt.Sprintf("Teleprinter alphabet to use (%s)", strings.Join(lorenz.AlphabetNames(), "|")))
+	inPtr := flag.String("in", "text", "Format of the message (text|binary|notation), binary and notation are read as raw ITA2 codes")
+	outPtr := flag.String("out", "", "Format of the output (text|binary|notation), notation for text input and text otherwise if not given")
+	unshiftPtr := flag.Bool("unshift", false, "Return to letter shift after every space")
+	tapeInPtr := flag.String("tapein", "", "File containing a punched tape dump to use in place of the message [optional]")
+	indicatorPtr := flag.String("indicator", "", "12 letter indicator (e.g. HQIBPEXEZMUG) or QEP number (e.g. 'QEP 17') in place of -chi, -psi and -mot [optional]")
+	qepBookPtr := flag.String("qepbook", "", "File holding the QEP book used to look up a QEP indicator, as written by 'lorenz genwheels -qep' [optional]")
+	lettersPtr := flag.String("letters", "", "File of wheel letter lists, as written by 'lorenz genwheels -letters', used to decode a 12 letter indicator [optional]")
+	tracePtr := flag.Bool("trace", false, "Print a table of every wheel position and the key for each character")
+	wheelsPtr := flag.String("wheels", "", "File of wheel patterns, as written by 'lorenz genwheels', in place of the standard patterns [optional]")
+	tapeOutPtr := flag.String("tapeout", "", "File to punch the output to, as an SVG image if ending in .svg, otherwise as ASCII art [optional]")
 
 	flag.Parse()
 
-	message, err := validateMessage(*messagePtr)
+	if *decryptPtr {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: -d is deprecated and ignored, give ciphertext with -in notation or -in binary to decrypt it")
+	}
+
+	wheels, err := readWheelSet(*wheelsPtr)
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Invalid characters in message, can only include A-Z, 0-9")
+		_, _ = fmt.Fprintf(os.Stderr, "Error for wheel patterns: %s\n", err)
+		os.Exit(1)
 	}
 
-	chiWheels, err := validateChiPsiPositions(*chiPositionsPtr, lorenz.NewWheelSet().Chi)
+	chiWheels, err := validateChiPsiPositions(*chiPositionsPtr, wheels.Chi)
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Error for chi wheels: %s", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error for chi wheels: %s\n", err)
 	}
 
-	psiWheels, err := validateChiPsiPositions(*psiPositionsPtr, lorenz.NewWheelSet().Psi)
+	psiWheels, err := validateChiPsiPositions(*psiPositionsPtr, wheels.Psi)
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Error for psi wheels: %s", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error for psi wheels: %s\n", err)
 	}
 
-	motorWheels, err := validateMotorPositions(*mPositionsPtr)
+	motorWheels, err := validateMotorPositions(*mPositionsPtr, wheels.Motor)
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Error for motor wheels: %s", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error for motor wheels: %s\n", err)
 	}
 
 	machine := lorenz.NewLorenz(chiWheels, motorWheels, psiWheels)
 
-	alphabet := lorenz.NewITA2LSB()
-	encoded, _ := alphabet.AsciiToITA2(message, *decryptPtr)
-	encrypted := machine.Encrypt(encoded)
-	decoded, _ := alphabet.ITA2ToAscii(encrypted, *decryptPtr)
+	if *indicatorPtr != "" {
+		start, err := validateIndicatorInput(*indicatorPtr, *qepBookPtr, *lettersPtr)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for indicator: %s\n", err)
+			os.Exit(1)
+		}
+		machine.SetStart(start)
+	}
+
+	alphabet, err := lorenz.NewAlphabet(*alphabetPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for alphabet: %s\n", err)
+		os.Exit(1)
+	}
+
+	var encoded []byte
+	if *tapeInPtr != "" {
+		encoded, err = readTape(*tapeInPtr)
+	} else {
+		encoder := lorenz.NewEncoder(alphabet)
+		encoder.UnshiftOnSpace = *unshiftPtr
+		encoded, err = lorenz.EncodeFormat(*inPtr, *messagePtr, &encoder)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for message: %s\n", err)
+		os.Exit(1)
+	}
+
+	var encrypted []byte
+	if *tracePtr {
+		var trace []lorenz.TraceStep
+		encrypted, trace = machine.EncryptTrace(encoded)
+		fmt.Print(lorenz.FormatTrace(trace))
+	} else {
+		encrypted = machine.Encrypt(encoded)
+	}
+
+	if *tapeOutPtr != "" {
+		if err := writeTape(*tapeOutPtr, encrypted); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for output tape: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *outPtr == "" {
+		*outPtr = lorenz.OutputFormat(*inPtr)
+		if *tapeInPtr != "" {
+			*outPtr = "text"
+		}
+	}
+	// Text written out from text keeps its shift codes as stand-ins, so that it can be read back in.
+	decoder := lorenz.NewDecoder(alphabet)
+	decoder.ShowShifts = *tapeInPtr == "" && *inPtr == "text" && *outPtr == "text"
+	decoder.UnshiftOnSpace = *unshiftPtr
+	decoded, err := lorenz.DecodeFormat(*outPtr, encrypted, &decoder)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for output: %s\n", err)
+		os.Exit(1)
+	}
 	fmt.Printf("%s\n", decoded)
 
 }
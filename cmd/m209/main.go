@@ -0,0 +1,68 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/m209"
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readSettings reads the key list file holding the pin and lug settings.
+//
+// Errors
+//
+// The returned error will not be nil if no file is given, it cannot be read, or it does not hold valid settings.
+func readSettings(path string) (m209.Settings, error) {
+	if path == "" {
+		return m209.Settings{}, errors.New("a settings file must be given with -settings")
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return m209.Settings{}, err
+	}
+	return m209.ParseSettings(string(text))
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+	settingsPtr := flag.String("settings", "", "File holding the lug and pin settings")
+	positionsPtr := flag.String("pos", "AAAAAA", "The letter shown by each of the six wheels")
+	decryptPtr := flag.Bool("d", false, "Whether you are seeking to decrypt a message")
+
+	flag.Parse()
+
+	settings, err := readSettings(*settingsPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for settings: %s\n", err)
+		os.Exit(1)
+	}
+
+	machine, err := m209.NewM209(settings)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for settings: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := machine.SetPositions(*positionsPtr); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for positions: %s\n", err)
+		os.Exit(1)
+	}
+
+	message := strings.ToUpper(*messagePtr)
+	var output string
+	if *decryptPtr {
+		output, err = machine.Decrypt(message)
+	} else {
+		output, err = machine.Encrypt(message)
+		output = util.FormatGroups(output, 5, 0)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/nema"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// validateWheelsInput takes the user's choice of wheels and positions and returns the NEMA with its drum assembled.
+//
+// Errors
+//
+// The returned error will not be nil if:
+//	- There are not 4 contact wheels, 5 red wheels or 10 positions
+//	- A wheel is not in the wheel set, or is used more than once
+//	- A position is not a letter A-Z
+//
+func validateWheelsInput(set nema.WheelSet, contacts string, reds string, positions string) (nema.NEMA, error) {
+	machine := nema.NEMA{UKW: set.UKW}
+	contactNames := strings.Fields(contacts)
+	redNames := strings.Fields(reds)
+	positions = strings.ToUpper(positions)
+	if len(contactNames) != 4 || len(redNames) != 5 || len(positions) != 10 {
+		return machine, errors.New("4 contact wheels, 5 red wheels and 10 positions must be given")
+	}
+	if !enigma.LatinAlphabet().Valid(positions) {
+		return machine, errors.New("positions must be letters A-Z")
+	}
+
+	used := make(map[string]bool)
+	for _, name := range append(contactNames, redNames...) {
+		if used[name] {
+			return machine, fmt.Errorf("wheel %s is used more than once", name)
+		}
+		used[name] = true
+	}
+
+	// Positions are given for the wheels from left to right: UKW, then each contact wheel and its red wheel, then the drive wheel.
+	machine.UKW.SetShownPos(positions[0] - 'A' + 1)
+	for idx, name := range contactNames {
+		wheel, err := set.ContactWheel(name)
+		if err != nil {
+			return machine, err
+		}
+		wheel.SetShownPos(positions[1+2*idx] - 'A' + 1)
+		machine.ContactWheels[idx] = wheel
+	}
+	for idx, name := range redNames {
+		wheel, err := set.RedWheel(name)
+		if err != nil {
+			return machine, err
+		}
+		if idx < len(machine.RedWheels) {
+			_ = wheel.SetPos(positions[2+2*idx] - 'A')
+			machine.RedWheels[idx] = wheel
+		} else {
+			_ = wheel.SetPos(positions[len(positions)-1] - 'A')
+			machine.DriveWheel = wheel
+		}
+	}
+	return machine, nil
+}
+
+// readWheelSet returns the named wheel set, or the wheel set in a catalog file if a path is given.
+//
+// Errors
+//
+// The returned error will not be nil if the wheel set is not known, or the catalog cannot be read or is not a NEMA wheel set.
+func readWheelSet(name string, catalogPath string) (nema.WheelSet, error) {
+	if catalogPath == "" {
+		return nema.NewWheelSet(name)
+	}
+	text, err := os.ReadFile(catalogPath)
+	if err != nil {
+		return nema.WheelSet{}, err
+	}
+	return nema.ParseWheelSet(catalogPath, string(text))
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+	setPtr := flag.String("set", "training", fmt.Sprintf("Wheel set to use (%s)", strings.Join(nema.WheelSetNames(), "|")))
+	contactPtr := flag.String("contact", "1 2 3 4", "Contact wheels from left to right")
+	redPtr := flag.String("red", "5 6 7 8 9", "Red wheels from left to right, the last being the drive wheel")
+	catalogPtr := flag.String("catalog", "", "JSON or YAML catalog of a wheel set to use instead of -set [optional]")
+	positionsPtr := flag.String("pos", "AAAAAAAAAA", "Letter shown by each of the 10 wheels from left to right, starting with the UKW")
+
+	flag.Parse()
+
+	set, err := readWheelSet(*setPtr, *catalogPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for wheel set: %s\n", err)
+		os.Exit(1)
+	}
+
+	machine, err := validateWheelsInput(set, *contactPtr, *redPtr, *positionsPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for wheels: %s\n", err)
+		os.Exit(1)
+	}
+
+	message := strings.Replace(strings.ToUpper(*messagePtr), " ", "", -1)
+
+	if !enigma.LatinAlphabet().Valid(message) {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid characters in message: %s\n", message)
+		os.Exit(1)
+	}
+
+	cipher, err := machine.Encrypt(message)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(cipher)
+}
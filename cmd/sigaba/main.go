@@ -0,0 +1,117 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/sigaba"
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// validateBankInput takes the user's rotor selection and positions for a bank of five rotors
+// and returns the rotors set to those positions.
+// Rotors are given by number, with R appended for a rotor inserted reversed, and positions as letters or digits.
+//
+// Errors
+//
+// The returned error will not be nil if:
+//	- There are not 5 rotors or 5 positions
+//	- A rotor number is not in the set, or is already used in another bank
+//	- A position is not on the rotor
+//
+func validateBankInput(selection string, positions string, set []enigma.Rotor, used []bool, digits bool) ([5]enigma.Rotor, error) {
+	var bank [5]enigma.Rotor
+	rotors := strings.Fields(selection)
+	if len(rotors) != len(bank) || len(positions) != len(bank) {
+		return bank, errors.New("5 rotors and 5 positions must be given")
+	}
+
+	for idx, name := range rotors {
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.ToUpper(name), "R"))
+		if err != nil || number < 0 || number >= len(set) {
+			return bank, fmt.Errorf("rotor %s is not valid", name)
+		}
+		if used[number] {
+			return bank, fmt.Errorf("rotor %d is used more than once", number)
+		}
+		used[number] = true
+
+		rotor := set[number]
+		if strings.HasSuffix(strings.ToUpper(name), "R") {
+			if digits {
+				return bank, errors.New("index rotors cannot be reversed")
+			}
+			rotor = rotor.Reversed()
+		}
+
+		pos := int(strings.ToUpper(positions)[idx]) - 'A'
+		if digits {
+			pos = int(positions[idx]) - '0'
+		}
+		if pos < 0 || pos >= len(rotor.Wires) {
+			return bank, fmt.Errorf("position %c is not on rotor %s", positions[idx], name)
+		}
+		rotor.SetShownPos(byte(pos + 1))
+		bank[idx] = rotor
+	}
+	return bank, nil
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+	cipherPtr := flag.String("cipher", "0 1 2 3 4", "Cipher rotors (0-9, with R appended if reversed) from left to right")
+	controlPtr := flag.String("control", "5 6 7 8 9", "Control rotors (0-9, with R appended if reversed) from left to right")
+	indexPtr := flag.String("index", "0 1 2 3 4", "Index rotors (0-4) from left to right")
+	cipherPosPtr := flag.String("cipherpos", "AAAAA", "Letter shown by each cipher rotor")
+	controlPosPtr := flag.String("controlpos", "AAAAA", "Letter shown by each control rotor")
+	indexPosPtr := flag.String("indexpos", "00000", "Digit shown by each index rotor")
+	decryptPtr := flag.Bool("d", false, "Whether you are seeking to decrypt a message")
+
+	flag.Parse()
+
+	rotorSet := sigaba.GenerateRotors()
+	used := make([]bool, len(rotorSet.Rotors))
+
+	cipherRotors, err := validateBankInput(*cipherPtr, *cipherPosPtr, rotorSet.Rotors[:], used, false)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for cipher rotors: %s\n", err)
+		os.Exit(1)
+	}
+
+	controlRotors, err := validateBankInput(*controlPtr, *controlPosPtr, rotorSet.Rotors[:], used, false)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for control rotors: %s\n", err)
+		os.Exit(1)
+	}
+
+	indexRotors, err := validateBankInput(*indexPtr, *indexPosPtr, rotorSet.IndexRotors[:], make([]bool, len(rotorSet.IndexRotors)), true)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for index rotors: %s\n", err)
+		os.Exit(1)
+	}
+
+	machine := sigaba.SIGABA{
+		CipherRotors:  cipherRotors,
+		ControlRotors: controlRotors,
+		IndexRotors:   indexRotors,
+	}
+
+	message := strings.ToUpper(*messagePtr)
+	var output string
+	if *decryptPtr {
+		output, err = machine.Decrypt(message)
+	} else {
+		output, err = machine.Encrypt(message)
+		output = util.FormatGroups(output, 5, 0)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
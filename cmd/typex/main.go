@@ -0,0 +1,135 @@
+package main
+
+import (
+	"EnigmaLorenz/pkg/typex"
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+import "EnigmaLorenz/pkg/enigma"
+
+// validateRotorInput takes the user's rotor parameter and returns the corresponding Rotor from the registry.
+// An error is returned in cases where the input is not valid.
+//
+// Errors
+//
+// The returned error will not be nil if:
+//	- There are not 3 arguments seperated by a space
+//	- The first argument is not one of the registered rotors (A-H), optionally followed by R for a reversed insert
+//	- The rotor setting is not between 1 and 26 inclusively
+//	- The ring setting is not between 0 and 25 inclusively
+//
+func validateRotorInput(input string, registry *enigma.Registry) (enigma.Rotor, error) {
+	args := strings.Split(input, " ")
+
+	if len(args) != 3 {
+		return enigma.Rotor{}, errors.New("incorrect number of arguments")
+	}
+
+	// Validate rotor wheel
+	name := strings.TrimSuffix(args[0], "R")
+	rotor, err := registry.Rotor(name)
+	if err != nil {
+		return rotor, err
+	}
+	if name != args[0] {
+		rotor = typex.Reverse(rotor)
+	}
+
+	// Validate rotor position
+	pos, err := strconv.Atoi(args[1])
+	if err != nil {
+		return rotor, err
+	}
+	if pos < 1 || pos > 26 {
+		return rotor, errors.New("rotor position not between 1 and 26")
+	}
+
+	rotor.SetShownPos(byte(pos))
+
+	// Validate ring setting
+	ring, err := strconv.Atoi(args[2])
+	if err != nil {
+		return rotor, err
+	}
+	if ring < 0 || ring > 25 {
+		return rotor, errors.New("ring setting not between 0 and 25")
+	}
+
+	rotor.SetRingSetting(byte(ring))
+
+	return rotor, nil
+}
+
+func main() {
+	messagePtr := flag.String("m", "", "The message to be encrypted/decrypted")
+
+	leftRotorPtr := flag.String("l", "A 1 0", "Left rotor (A-H, with R appended if reversed), position (1-26), and ring setting (0-25)")
+	centerRotorPtr := flag.String("c", "B 1 0", "Center rotor (A-H, with R appended if reversed), position (1-26), and ring setting (0-25)")
+	rightRotorPtr := flag.String("r", "C 1 0", "Right rotor (A-H, with R appended if reversed), position (1-26), and ring setting (0-25)")
+	leftStatorPtr := flag.String("sl", "D 1 0", "Left stator (A-H, with R appended if reversed), position (1-26), and ring setting (0-25)")
+	rightStatorPtr := flag.String("sr", "E 1 0", "Right stator (A-H, with R appended if reversed), position (1-26), and ring setting (0-25)")
+	reflectorPtr := flag.String("ukw", typex.ExampleReflector, "Reflector as 13 pairs of letters in the form of 'AB CD ...'")
+	entryPtr := flag.String("entry", "", "Entry permutation as the 26 letters wired to A-Z [optional]")
+
+	flag.Parse()
+
+	rotors := map[string]*string{
+		"left rotor":   leftRotorPtr,
+		"center rotor": centerRotorPtr,
+		"right rotor":  rightRotorPtr,
+		"left stator":  leftStatorPtr,
+		"right stator": rightStatorPtr,
+	}
+	registry := typex.GenerateRotors().Registry()
+	validated := make(map[string]enigma.Rotor)
+	for name, input := range rotors {
+		rotor, err := validateRotorInput(*input, registry)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error for %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		validated[name] = rotor
+	}
+
+	reflector, err := typex.NewReflector(*reflectorPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for reflector: %s\n", err)
+		os.Exit(1)
+	}
+
+	entry, err := typex.NewEntry(*entryPtr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error for entry: %s\n", err)
+		os.Exit(1)
+	}
+
+	machine := typex.Typex{
+		LeftRotor:   validated["left rotor"],
+		CenterRotor: validated["center rotor"],
+		RightRotor:  validated["right rotor"],
+		LeftStator:  validated["left stator"],
+		RightStator: validated["right stator"],
+		Reflector:   reflector,
+		Entry:       entry,
+	}
+
+	message := strings.Replace(strings.ToUpper(*messagePtr), " ", "", -1)
+
+	if !util.ValidChars(message, false) {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid characters in message: %s\n", message)
+		os.Exit(1)
+	}
+
+	cipher, err := machine.Encrypt(message)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Encryption failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(cipher)
+}
@@ -0,0 +1,247 @@
+// Package convention converts free text to and from the way German military operators wrote plaintext for the Enigma,
+// whose keyboard had only the letters A-Z.
+//
+// Umlauts are written out (Ä as AE, ß as SS), CH is shortened to Q, and punctuation is replaced by letters:
+// X for a full stop, Y for a comma, XX for a colon and UD for a question mark.
+// The Army spelled out each digit (EINS, ZWO, ...) and wrote brackets as YY,
+// while the Navy wrote brackets as J and numbers in Y-figures, the letters above each digit on the keyboard between two Ys.
+package convention
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A Convention is the set of rules a service used for writing plaintext.
+type Convention int
+
+const (
+	// Heer is the Army convention, spelling out digits and writing brackets as YY.
+	Heer Convention = iota
+	// Kriegsmarine is the Navy convention, writing numbers in Y-figures and brackets as J.
+	Kriegsmarine
+)
+
+// ConventionNames returns the names accepted by ParseConvention, in the order of the Convention values.
+func ConventionNames() []string {
+	return []string{"heer", "kriegsmarine"}
+}
+
+// ParseConvention returns the Convention with the given name, which is one of ConventionNames.
+//
+// # Errors
+//
+// An error is returned if the name is not a known convention.
+func ParseConvention(name string) (Convention, error) {
+	for idx, conventionName := range ConventionNames() {
+		if strings.EqualFold(name, conventionName) {
+			return Convention(idx), nil
+		}
+	}
+	return Heer, fmt.Errorf("unknown convention %s, must be one of %s", name, strings.Join(ConventionNames(), "|"))
+}
+
+// String returns the name of the Convention.
+func (c Convention) String() string {
+	if int(c) < 0 || int(c) >= len(ConventionNames()) {
+		return "unknown"
+	}
+	return ConventionNames()[c]
+}
+
+// spelledDigits are the words the Army used for the digits 0-9. ZWO was used in place of ZWEI so it could not be misheard as DREI.
+var spelledDigits = [10]string{"NULL", "EINS", "ZWO", "DREI", "VIER", "FUENF", "SECHS", "SIEBEN", "ACHT", "NEUN"}
+
+// figureLetters are the letters above the digits 0-9 on the top row of the German keyboard, used by the Navy for numbers.
+const figureLetters = "PQWERTZUIO"
+
+// brackets returns how the Convention writes a bracket.
+func (c Convention) brackets() string {
+	if c == Kriegsmarine {
+		return "J"
+	}
+	return "YY"
+}
+
+// punctuation holds the letters written for punctuation by both conventions.
+var punctuation = map[rune]string{
+	'.': "X",
+	',': "Y",
+	':': "XX",
+	'?': "UD",
+}
+
+// letters holds the letters written out for the German letters not on the Enigma keyboard.
+var letters = map[rune]string{
+	'Ä': "AE",
+	'Ö': "OE",
+	'Ü': "UE",
+	'ß': "SS",
+	'ẞ': "SS",
+}
+
+// Normalize converts free English or German text to the Convention, giving text of the letters A-Z only.
+// Spaces, quotes and apostrophes are dropped, as operators did not send them.
+//
+// # Errors
+//
+// An error is returned if the text has a character the Convention has no way of writing.
+func Normalize(text string, convention Convention) (string, error) {
+	var normalized strings.Builder
+	runes := []rune(strings.ToUpper(text))
+	for idx := 0; idx < len(runes); idx++ {
+		chr := runes[idx]
+		switch {
+		case chr >= 'A' && chr <= 'Z':
+			normalized.WriteRune(chr)
+		case chr >= '0' && chr <= '9' && convention == Kriegsmarine:
+			normalized.WriteByte('Y')
+			for ; idx < len(runes) && runes[idx] >= '0' && runes[idx] <= '9'; idx++ {
+				normalized.WriteByte(figureLetters[runes[idx]-'0'])
+			}
+			idx--
+			normalized.WriteByte('Y')
+		case chr >= '0' && chr <= '9':
+			normalized.WriteString(spelledDigits[chr-'0'])
+		case chr == '(' || chr == ')':
+			normalized.WriteString(convention.brackets())
+		case letters[chr] != "":
+			normalized.WriteString(letters[chr])
+		case punctuation[chr] != "":
+			normalized.WriteString(punctuation[chr])
+		case unicode.IsSpace(chr) || strings.ContainsRune("'\"‘’“”„", chr):
+		default:
+			return "", fmt.Errorf("character %c cannot be written in the %s convention", chr, convention)
+		}
+	}
+	return strings.ReplaceAll(normalized.String(), "CH", "Q"), nil
+}
+
+// Restore converts text written in the Convention back into readable text, as the receiving operator would have read it.
+//
+// The conventions lose information, so this is a best guess: spaces between words cannot be restored,
+// a Q not followed by U is taken to be CH, and X, Y, UD, spelled out digits and the Navy's J are always taken
+// to be punctuation or digits, even where they were part of a word.
+//
+// # Errors
+//
+// An error is returned if the text is not only the letters A-Z.
+func Restore(text string, convention Convention) (string, error) {
+	for _, chr := range text {
+		if chr < 'A' || chr > 'Z' {
+			return "", errors.New("text to restore must be capitalized ascii letters only")
+		}
+	}
+
+	var restored strings.Builder
+	open := false
+	for idx := 0; idx < len(text); {
+		// Y-figures are read first, as the figure for 1 is a Q that must not be taken as CH.
+		if convention == Kriegsmarine {
+			if digits, length := readFigures(text[idx:]); length > 0 {
+				restored.WriteString(digits)
+				idx += length
+				continue
+			}
+		}
+		end := len(text)
+		if convention == Kriegsmarine {
+			if next := strings.IndexByte(text[idx+1:], 'Y'); next != -1 {
+				end = idx + 1 + next
+			}
+		}
+		restoreWords(&restored, expandQ(text[idx:end]), convention, &open)
+		idx = end
+	}
+	return strings.TrimSpace(restored.String()), nil
+}
+
+// expandQ replaces each Q not followed by U with CH.
+func expandQ(text string) string {
+	var expanded strings.Builder
+	for idx := range []byte(text) {
+		if text[idx] == 'Q' && !strings.HasPrefix(text[idx:], "QU") {
+			expanded.WriteString("CH")
+		} else {
+			expanded.WriteByte(text[idx])
+		}
+	}
+	return expanded.String()
+}
+
+// restoreWords writes text with its spelled out digits, brackets and punctuation restored.
+// open tracks whether a bracket has been opened and not yet closed.
+func restoreWords(restored *strings.Builder, text string, convention Convention, open *bool) {
+	for idx := 0; idx < len(text); {
+		rest := text[idx:]
+		if convention == Heer {
+			if digit, length := readSpelledDigit(rest); length > 0 {
+				restored.WriteString(digit)
+				idx += length
+				continue
+			}
+		}
+		if bracket := convention.brackets(); strings.HasPrefix(rest, bracket) {
+			if *open {
+				restored.WriteString(") ")
+			} else {
+				restored.WriteString(" (")
+			}
+			*open = !*open
+			idx += len(bracket)
+			continue
+		}
+		if mark, length := readPunctuation(rest); length > 0 {
+			restored.WriteString(mark + " ")
+			idx += length
+			continue
+		}
+		restored.WriteByte(text[idx])
+		idx++
+	}
+}
+
+// readFigures returns the digits of a number in Y-figures at the start of text, and the number of letters it takes up.
+// A length of 0 is returned if text does not start with a number.
+func readFigures(text string) (string, int) {
+	if !strings.HasPrefix(text, "Y") {
+		return "", 0
+	}
+	end := strings.IndexByte(text[1:], 'Y')
+	if end < 1 {
+		return "", 0
+	}
+	digits := make([]byte, end)
+	for idx := range digits {
+		digit := strings.IndexByte(figureLetters, text[1+idx])
+		if digit == -1 {
+			return "", 0
+		}
+		digits[idx] = byte('0' + digit)
+	}
+	return string(digits), end + 2
+}
+
+// readSpelledDigit returns the digit spelled out at the start of text, and the number of letters it takes up.
+// A length of 0 is returned if text does not start with a spelled out digit.
+func readSpelledDigit(text string) (string, int) {
+	for digit, word := range spelledDigits {
+		if strings.HasPrefix(text, word) {
+			return string(rune('0' + digit)), len(word)
+		}
+	}
+	return "", 0
+}
+
+// readPunctuation returns the punctuation mark written at the start of text, and the number of letters it takes up.
+// A length of 0 is returned if text does not start with punctuation. XX is read before X so that a colon is not two full stops.
+func readPunctuation(text string) (string, int) {
+	for _, mark := range []rune{':', '.', ',', '?'} {
+		if written := punctuation[mark]; strings.HasPrefix(text, written) {
+			return string(mark), len(written)
+		}
+	}
+	return "", 0
+}
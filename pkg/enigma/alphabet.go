@@ -0,0 +1,133 @@
+package enigma
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// latinLetters is the alphabet of the standard Enigma keyboard.
+const latinLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// An Alphabet is the ordered set of symbols on a machine's keyboard, with one contact on each rotor per symbol.
+// A symbol's index in the Alphabet is the contact it connects to, so rotors used with an Alphabet must have one wire per symbol.
+//
+// The zero value is the 26 letter Alphabet A-Z, so machines built without giving an Alphabet work as the standard Enigma.
+type Alphabet struct {
+	Name    string
+	symbols string
+}
+
+// NewAlphabet creates an Alphabet from a string of symbols, such as "0123456789" for the Enigma Z30.
+//
+// # Errors
+//
+// An error is returned if there are fewer than 2 symbols, a symbol is not printable ASCII or a symbol appears twice.
+func NewAlphabet(name string, symbols string) (Alphabet, error) {
+	alphabet := Alphabet{Name: name}
+	if len(symbols) < 2 {
+		return alphabet, errors.New("alphabet must have at least 2 symbols")
+	}
+	for idx := range []byte(symbols) {
+		if symbols[idx] <= ' ' || symbols[idx] > '~' {
+			return alphabet, errors.New("alphabet symbols must be printable ascii characters")
+		}
+		if strings.IndexByte(symbols[:idx], symbols[idx]) != -1 {
+			return alphabet, fmt.Errorf("symbol %c appears more than once in the alphabet", symbols[idx])
+		}
+	}
+	alphabet.symbols = symbols
+	return alphabet, nil
+}
+
+// LatinAlphabet returns the 26 letter Alphabet A-Z used by the standard Enigma.
+func LatinAlphabet() Alphabet {
+	return Alphabet{Name: "latin", symbols: latinLetters}
+}
+
+// DigitAlphabet returns the 10 digit Alphabet 0-9 used by the Enigma Z30.
+func DigitAlphabet() Alphabet {
+	return Alphabet{Name: "digits", symbols: "0123456789"}
+}
+
+// Symbols returns the symbols of the Alphabet in order.
+func (a Alphabet) Symbols() string {
+	if a.symbols == "" {
+		return latinLetters
+	}
+	return a.symbols
+}
+
+// Size returns the number of symbols in the Alphabet.
+func (a Alphabet) Size() int {
+	return len(a.Symbols())
+}
+
+// Index returns the index of a symbol in the Alphabet, and whether the symbol is in the Alphabet.
+func (a Alphabet) Index(symbol byte) (byte, bool) {
+	idx := strings.IndexByte(a.Symbols(), symbol)
+	return byte(idx), idx != -1
+}
+
+// Symbol returns the symbol at an index of the Alphabet.
+func (a Alphabet) Symbol(idx byte) byte {
+	return a.Symbols()[idx]
+}
+
+// Valid returns whether text only holds symbols of the Alphabet.
+func (a Alphabet) Valid(text string) bool {
+	for idx := range []byte(text) {
+		if _, ok := a.Index(text[idx]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Indices converts text into the index of each of its symbols.
+//
+// # Errors
+//
+// An error is returned if a symbol is not in the Alphabet.
+func (a Alphabet) Indices(text string) ([]byte, error) {
+	indices := make([]byte, len(text))
+	for idx := range indices {
+		symbolIdx, ok := a.Index(text[idx])
+		if !ok {
+			return []byte{}, fmt.Errorf("symbol %c is not in the alphabet", text[idx])
+		}
+		indices[idx] = symbolIdx
+	}
+	return indices, nil
+}
+
+// NewRotor creates a Rotor for an Alphabet, with wiring given as the symbols wired to each symbol of the Alphabet in order,
+// and notches given as the symbols shown when the rotor will carry the next rotor on.
+//
+// # Errors
+//
+// An error is returned if the wiring is not a permutation of the Alphabet or a notch is not in the Alphabet.
+func NewRotor(name string, alphabet Alphabet, wiring string, notches string) (Rotor, error) {
+	rotor := Rotor{Name: name, Alphabet: alphabet}
+	if len(wiring) != alphabet.Size() {
+		return rotor, fmt.Errorf("rotor %s must have %d wires", name, alphabet.Size())
+	}
+	wires, err := alphabet.Indices(wiring)
+	if err != nil {
+		return rotor, err
+	}
+	seen := make([]bool, len(wires))
+	for _, wire := range wires {
+		if seen[wire] {
+			return rotor, fmt.Errorf("rotor %s wires two contacts to the same symbol", name)
+		}
+		seen[wire] = true
+	}
+	turnover, err := alphabet.Indices(notches)
+	if err != nil {
+		return rotor, err
+	}
+	rotor.Wires = wires
+	rotor.TurnoverList = turnover
+	return rotor, nil
+}
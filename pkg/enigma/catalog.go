@@ -0,0 +1,349 @@
+package enigma
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A CatalogEntry describes a rotor or reflector in a catalog file.
+// Wiring gives the symbol wired to each symbol of the alphabet in order, e.g. "EKMFLGDQVZNTOWYHXUSPAIBRCJ" for rotor I,
+// and Notches gives the symbols shown when the rotor will carry the next rotor on.
+// Alphabet is optional and gives the symbols of a rotor that is not lettered A-Z.
+type CatalogEntry struct {
+	Name      string `json:"name"`
+	Wiring    string `json:"wiring"`
+	Notches   string `json:"notches"`
+	Reflector bool   `json:"reflector"`
+	Alphabet  string `json:"alphabet"`
+}
+
+// catalogFile is the layout of a JSON catalog, a list of entries under the key "rotors".
+type catalogFile struct {
+	Rotors []CatalogEntry `json:"rotors"`
+}
+
+// ParseCatalog reads the entries of a rotor catalog written in either JSON or YAML.
+// Both hold a list of entries under the key "rotors":
+//
+//	{"rotors": [{"name": "I", "wiring": "EKMFLGDQVZNTOWYHXUSPAIBRCJ", "notches": "Q"}]}
+//
+//	rotors:
+//	  - name: UKW-B
+//	    wiring: YRUHQSLDPXNGOKMIEBFZCWVJAT
+//	    reflector: true
+//
+// Only this simple form of YAML is understood: one key and plain or quoted value per line,
+// with '#' starting a comment when it is outside quotes and at the start of the line or after whitespace.
+//
+// # Errors
+//
+// An error is returned if the catalog cannot be read or an entry has no name or wiring.
+func ParseCatalog(text string) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	if strings.HasPrefix(strings.TrimSpace(text), "{") {
+		var file catalogFile
+		if err := json.Unmarshal([]byte(text), &file); err != nil {
+			return []CatalogEntry{}, err
+		}
+		entries = file.Rotors
+	} else {
+		var err error
+		entries, err = parseCatalogYAML(text)
+		if err != nil {
+			return []CatalogEntry{}, err
+		}
+	}
+
+	for idx, entry := range entries {
+		if entry.Name == "" {
+			return []CatalogEntry{}, fmt.Errorf("catalog entry %d has no name", idx+1)
+		}
+		if entry.Wiring == "" {
+			return []CatalogEntry{}, fmt.Errorf("catalog entry %s has no wiring", entry.Name)
+		}
+	}
+	return entries, nil
+}
+
+// parseCatalogYAML reads catalog entries from the simple YAML form described by ParseCatalog.
+func parseCatalogYAML(text string) ([]CatalogEntry, error) {
+	entries := []CatalogEntry{}
+	for num, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" || line == "rotors:" || line == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "-") {
+			entries = append(entries, CatalogEntry{})
+			line = strings.TrimSpace(line[1:])
+			if line == "" {
+				continue
+			}
+		}
+		if len(entries) == 0 {
+			return []CatalogEntry{}, fmt.Errorf("line %d is not part of a catalog entry", num+1)
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return []CatalogEntry{}, fmt.Errorf("line %d must be a key and a value", num+1)
+		}
+		value = strings.Trim(strings.TrimSpace(value), "\"'")
+		entry := &entries[len(entries)-1]
+		switch strings.TrimSpace(key) {
+		case "name":
+			entry.Name = value
+		case "wiring":
+			entry.Wiring = value
+		case "notches":
+			entry.Notches = value
+		case "alphabet":
+			entry.Alphabet = value
+		case "reflector":
+			reflector, err := strconv.ParseBool(value)
+			if err != nil {
+				return []CatalogEntry{}, fmt.Errorf("line %d must give reflector as true or false", num+1)
+			}
+			entry.Reflector = reflector
+		default:
+			return []CatalogEntry{}, fmt.Errorf("unknown key %s on line %d", strings.TrimSpace(key), num+1)
+		}
+	}
+	return entries, nil
+}
+
+// stripComment removes a YAML comment from the line.
+// A '#' only starts a comment outside quotes, at the start of the line or after whitespace,
+// so quoted values such as alphabets may hold '#'.
+func stripComment(line string) string {
+	var quote rune
+	for idx, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#' && (idx == 0 || line[idx-1] == ' ' || line[idx-1] == '\t'):
+			return line[:idx]
+		}
+	}
+	return line
+}
+
+// Rotor builds the Rotor described by the entry.
+//
+// # Errors
+//
+// An error is returned if the alphabet or wiring is invalid, a notch is not in the alphabet,
+// or the rotor fails Rotor.Validate or the reflector Rotor.ValidateReflector.
+func (entry CatalogEntry) Rotor() (Rotor, error) {
+	alphabet := LatinAlphabet()
+	if entry.Alphabet != "" {
+		var err error
+		if alphabet, err = NewAlphabet(entry.Name, entry.Alphabet); err != nil {
+			return Rotor{}, err
+		}
+	}
+	rotor, err := NewRotor(entry.Name, alphabet, entry.Wiring, entry.Notches)
+	if err != nil {
+		return rotor, err
+	}
+	if entry.Reflector {
+		return rotor, rotor.ValidateReflector()
+	}
+	return rotor, rotor.Validate()
+}
+
+// A Registry holds rotors and reflectors by name, so that they may be chosen by name.
+// The name a rotor is registered under is how it is chosen, which need not be the same as its Name,
+// e.g. the reflector UKW-B is registered as "B".
+type Registry struct {
+	rotors     map[string]Rotor
+	reflectors map[string]Rotor
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		rotors:     make(map[string]Rotor),
+		reflectors: make(map[string]Rotor),
+	}
+}
+
+// Registry returns a Registry of the rotors and reflectors in the RotorSet.
+// The rotors are registered as I-VIII, beta and gamma, and the reflectors as A, B, C, b and c.
+func (set RotorSet) Registry() *Registry {
+	registry := NewRegistry()
+	rotors := map[string]Rotor{
+		"I": set.I, "II": set.II, "III": set.III, "IV": set.IV, "V": set.V, "VI": set.VI, "VII": set.VII, "VIII": set.VIII,
+		"beta": set.Beta, "gamma": set.Gamma,
+	}
+	for name, rotor := range rotors {
+		_ = registry.AddRotor(name, rotor)
+	}
+	reflectors := map[string]Rotor{"A": set.UKW_A, "B": set.UKW_B, "C": set.UKW_C, "b": set.UKW_b, "c": set.UKW_c}
+	for name, reflector := range reflectors {
+		_ = registry.AddReflector(name, reflector)
+	}
+	return registry
+}
+
+// StandardRegistry returns the Registry of the standard RotorSet, along with the rotors of the Z30RotorSet
+// registered by their names Z30-I, Z30-II, Z30-III and Z30-UKW.
+func StandardRegistry() *Registry {
+	registry := GenerateRotors().Registry()
+	registry.MustLoad(z30Catalog)
+	return registry
+}
+
+// AddRotor registers a rotor under a name.
+//
+// # Errors
+//
+// An error is returned if the name is empty, a rotor is already registered under it, or the rotor fails Rotor.Validate.
+func (registry *Registry) AddRotor(name string, rotor Rotor) error {
+	if err := rotor.Validate(); err != nil {
+		return err
+	}
+	return registry.add(registry.rotors, "rotor", name, rotor)
+}
+
+// AddReflector registers a reflector under a name.
+//
+// # Errors
+//
+// An error is returned if the name is empty, a reflector is already registered under it,
+// or the reflector fails Rotor.ValidateReflector.
+func (registry *Registry) AddReflector(name string, reflector Rotor) error {
+	if err := reflector.ValidateReflector(); err != nil {
+		return err
+	}
+	return registry.add(registry.reflectors, "reflector", name, reflector)
+}
+
+func (registry *Registry) add(rotors map[string]Rotor, kind string, name string, rotor Rotor) error {
+	if name == "" {
+		return fmt.Errorf("%s must have a name", kind)
+	}
+	if _, exists := rotors[name]; exists {
+		return fmt.Errorf("%s %s is already registered", kind, name)
+	}
+	rotors[name] = rotor
+	return nil
+}
+
+// Load registers every rotor and reflector in a catalog, in either of the forms read by ParseCatalog.
+// Each is registered under the name given in the catalog.
+//
+// # Errors
+//
+// An error is returned if the catalog cannot be read, an entry is invalid or a name is already registered.
+// Entries before the one in error will have been registered.
+func (registry *Registry) Load(text string) error {
+	entries, err := ParseCatalog(text)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		rotor, err := entry.Rotor()
+		if err != nil {
+			return err
+		}
+		if entry.Reflector {
+			err = registry.AddReflector(entry.Name, rotor)
+		} else {
+			err = registry.AddRotor(entry.Name, rotor)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustLoad is like Load but panics if the catalog cannot be loaded.
+// It is meant for catalogs embedded in a package, which are known to be valid.
+func (registry *Registry) MustLoad(text string) {
+	if err := registry.Load(text); err != nil {
+		panic(fmt.Sprintf("loading catalog: %s", err))
+	}
+}
+
+// Rotor returns a copy of the rotor registered under a name, which may be set up without changing the registered rotor.
+//
+// # Errors
+//
+// An error is returned if no rotor is registered under the name.
+func (registry *Registry) Rotor(name string) (Rotor, error) {
+	rotor, exists := registry.rotors[name]
+	if !exists {
+		return Rotor{}, errors.New("rotor selection is invalid")
+	}
+	return copyRotor(rotor), nil
+}
+
+// Reflector returns a copy of the reflector registered under a name.
+//
+// # Errors
+//
+// An error is returned if no reflector is registered under the name.
+func (registry *Registry) Reflector(name string) (Rotor, error) {
+	reflector, exists := registry.reflectors[name]
+	if !exists {
+		return Rotor{}, errors.New("reflector selection is invalid")
+	}
+	return copyRotor(reflector), nil
+}
+
+// MustRotor is like Rotor but panics if no rotor is registered under the name.
+// It is meant for rotors of an embedded catalog, which are known to be registered.
+func (registry *Registry) MustRotor(name string) Rotor {
+	rotor, err := registry.Rotor(name)
+	if err != nil {
+		panic(fmt.Sprintf("rotor %s: %s", name, err))
+	}
+	return rotor
+}
+
+// MustReflector is like Reflector but panics if no reflector is registered under the name.
+// It is meant for reflectors of an embedded catalog, which are known to be registered.
+func (registry *Registry) MustReflector(name string) Rotor {
+	reflector, err := registry.Reflector(name)
+	if err != nil {
+		panic(fmt.Sprintf("reflector %s: %s", name, err))
+	}
+	return reflector
+}
+
+// RotorNames returns the names of the registered rotors in sorted order.
+func (registry *Registry) RotorNames() []string {
+	return sortedNames(registry.rotors)
+}
+
+// ReflectorNames returns the names of the registered reflectors in sorted order.
+func (registry *Registry) ReflectorNames() []string {
+	return sortedNames(registry.reflectors)
+}
+
+func sortedNames(rotors map[string]Rotor) []string {
+	names := make([]string, 0, len(rotors))
+	for name := range rotors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// copyRotor returns a copy of a rotor that shares no wiring or notches with the original.
+func copyRotor(rotor Rotor) Rotor {
+	rotor.Wires = append([]byte{}, rotor.Wires...)
+	rotor.TurnoverList = append([]byte{}, rotor.TurnoverList...)
+	return rotor
+}
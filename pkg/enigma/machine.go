@@ -1,12 +1,15 @@
 package enigma
 
 import (
-	"EnigmaLorenz/pkg/util"
 	"errors"
+	"fmt"
 )
 
 // An Enigma is the representation of the Plugboard and the list of Rotors associated with the machine.
 // A FourthRotor is optional when Encrypt is used with the useFourthRotor flag set to false
+//
+// The Alphabet gives the symbols on the keyboard, and every rotor must be lettered with the same Alphabet.
+// Left as the zero value it is the 26 letter Alphabet A-Z.
 type Enigma struct {
 	LeftRotor   Rotor
 	CenterRotor Rotor
@@ -14,6 +17,7 @@ type Enigma struct {
 	FourthRotor Rotor
 	Reflector   Rotor
 	Plugs       Plugboard
+	Alphabet    Alphabet
 }
 
 // Encrypt enciphers a plaintext string using the Enigma Rotor and Plugboard.
@@ -21,25 +25,37 @@ type Enigma struct {
 //
 // # Errors
 //
-// If the encryption cannot complete due to invalid characters then a non-fatal error is returned.
+// If the encryption cannot complete due to characters not in the Alphabet, a rotor lettered with a different Alphabet,
+// or a rotor, reflector or plugboard that fails validation, then a non-fatal error is returned.
 func (machine *Enigma) Encrypt(plaintext string, useFourthRotor bool) (string, error) {
-	if !util.ValidChars(plaintext, false) {
-		return "", errors.New("enigma input must be capitalized ascii letters only")
+	if !machine.Alphabet.Valid(plaintext) {
+		if machine.Alphabet.Symbols() == latinLetters {
+			return "", errors.New("enigma input must be capitalized ascii letters only")
+		}
+		return "", fmt.Errorf("enigma input must only contain the symbols %s", machine.Alphabet.Symbols())
+	}
+
+	path := []Rotor{machine.RightRotor, machine.CenterRotor, machine.LeftRotor}
+	if useFourthRotor {
+		path = append(path, machine.FourthRotor)
+	}
+	for _, rotor := range append(path, machine.Reflector) {
+		if len(rotor.Wires) != machine.Alphabet.Size() {
+			return "", fmt.Errorf("rotor %s must have %d wires to match the alphabet", rotor.Name, machine.Alphabet.Size())
+		}
+		if rotor.Alphabet.Symbols() != machine.Alphabet.Symbols() {
+			return "", fmt.Errorf("rotor %s is lettered %s, not %s", rotor.Name, rotor.Alphabet.Symbols(), machine.Alphabet.Symbols())
+		}
+	}
+	if err := machine.validate(useFourthRotor); err != nil {
+		return "", err
 	}
 
 	var cipher []byte
 	for _, chr := range []byte(plaintext) {
 		chr = machine.Plugs.Translate(chr)
-		chr = chr - byte('A')
-		if machine.CenterRotor.AtNotch() {
-			machine.LeftRotor.Rotate()
-			// Double stepping of center rotor
-			machine.CenterRotor.Rotate()
-		}
-		if machine.RightRotor.AtNotch() {
-			machine.CenterRotor.Rotate()
-		}
-		machine.RightRotor.Rotate()
+		chr, _ = machine.Alphabet.Index(chr)
+		machine.step()
 
 		path := []Rotor{machine.RightRotor, machine.CenterRotor, machine.LeftRotor}
 		if useFourthRotor {
@@ -56,7 +72,7 @@ func (machine *Enigma) Encrypt(plaintext string, useFourthRotor bool) (string, e
 			chr = path[rotorIndex].TranslateReverse(chr)
 		}
 
-		chr = chr + byte('A')
+		chr = machine.Alphabet.Symbol(chr)
 
 		chr = machine.Plugs.Translate(chr)
 
@@ -65,3 +81,16 @@ func (machine *Enigma) Encrypt(plaintext string, useFourthRotor bool) (string, e
 	}
 	return string(cipher), nil
 }
+
+// step advances the rotors as a single key press would, before the signal passes through them.
+func (machine *Enigma) step() {
+	if machine.CenterRotor.AtNotch() {
+		machine.LeftRotor.Rotate()
+		// Double stepping of center rotor
+		machine.CenterRotor.Rotate()
+	}
+	if machine.RightRotor.AtNotch() {
+		machine.CenterRotor.Rotate()
+	}
+	machine.RightRotor.Rotate()
+}
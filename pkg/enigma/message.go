@@ -0,0 +1,236 @@
+package enigma
+
+import (
+	"EnigmaLorenz/pkg/util"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Layout is a way of setting out ciphertext for sending.
+type Layout int
+
+const (
+	// LayoutGroups sets out the ciphertext alone in groups of five letters.
+	LayoutGroups Layout = iota
+	// LayoutHeer sets out a message as the Army did, with a header line giving the call signs, time, letter count
+	// and the two three letter indicator groups, followed by the text in groups of five letters.
+	// A Kenngruppe, the group identifying the key net, is sent as the first group of the text.
+	LayoutHeer
+	// LayoutKriegsmarine sets out a message as the Navy did, with a header line giving the call sign, time and letter count,
+	// followed by the text in groups of four letters with the four letter indicator group sent first and repeated last.
+	LayoutKriegsmarine
+)
+
+// LayoutNames returns the names accepted by ParseLayout, in the order of the Layout values.
+func LayoutNames() []string {
+	return []string{"groups", "heer", "kriegsmarine"}
+}
+
+// ParseLayout returns the Layout with the given name, which is one of LayoutNames.
+//
+// # Errors
+//
+// An error is returned if the name is not a known layout.
+func ParseLayout(name string) (Layout, error) {
+	for idx, layoutName := range LayoutNames() {
+		if strings.EqualFold(name, layoutName) {
+			return Layout(idx), nil
+		}
+	}
+	return LayoutGroups, fmt.Errorf("unknown layout %s, must be one of %s", name, strings.Join(LayoutNames(), "|"))
+}
+
+// String returns the name of the Layout.
+func (l Layout) String() string {
+	if int(l) < 0 || int(l) >= len(LayoutNames()) {
+		return "unknown"
+	}
+	return LayoutNames()[l]
+}
+
+// groupSize returns the number of letters in each group of text for the Layout.
+func (l Layout) groupSize() int {
+	if l == LayoutKriegsmarine {
+		return 4
+	}
+	return 5
+}
+
+// FormatGroups splits text into groups of size letters separated by spaces, with groupsPerLine groups on each line.
+// If groupsPerLine is 0 or less the groups are not wrapped. The last group may be short.
+// The groups are made by util.FormatGroups, which every machine uses to group its ciphertext.
+func FormatGroups(text string, size int, groupsPerLine int) string {
+	return util.FormatGroups(text, size, groupsPerLine)
+}
+
+// A Message is a ciphertext together with the details sent in its header.
+//
+// From and To are call signs, and To is only sent in the Heer layout. Time is the time of origin as four digits.
+// Indicator is the two three letter groups "EHZ TBS" of the Heer layout, giving the indicator setting and enciphered message key,
+// or the single four letter indicator group of the Kriegsmarine layout.
+// Kenngruppe is the five letter group identifying the key net in the Heer layout, and may be left empty.
+// Text is the ciphertext alone, without spaces.
+type Message struct {
+	From       string
+	To         string
+	Time       string
+	Indicator  string
+	Kenngruppe string
+	Text       string
+}
+
+// letters returns whether text is made only of the letters A-Z.
+func letters(text string) bool {
+	for _, chr := range text {
+		if chr < 'A' || chr > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// check returns an error if the Message's details cannot be sent in the Layout.
+func (m Message) check(layout Layout) error {
+	if !letters(m.Text) {
+		return errors.New("message text must be capitalized ascii letters only")
+	}
+	if layout == LayoutGroups {
+		return nil
+	}
+	if len(m.Time) != 4 {
+		return errors.New("message time must be four digits")
+	}
+	if _, err := strconv.Atoi(m.Time); err != nil {
+		return errors.New("message time must be four digits")
+	}
+	callSigns := []string{m.From}
+	if layout == LayoutHeer {
+		callSigns = append(callSigns, m.To)
+	}
+	for _, callSign := range callSigns {
+		if callSign == "" || strings.ContainsAny(callSign, " =") {
+			return errors.New("call signs must be given without spaces or '='")
+		}
+	}
+
+	if layout == LayoutKriegsmarine {
+		if len(m.Indicator) != 4 || !letters(m.Indicator) {
+			return errors.New("kriegsmarine indicator must be one group of four letters")
+		}
+		if m.Kenngruppe != "" {
+			return errors.New("kriegsmarine messages do not have a separate kenngruppe")
+		}
+		return nil
+	}
+	groups := strings.Fields(m.Indicator)
+	if len(groups) != 2 || len(groups[0]) != 3 || len(groups[1]) != 3 || !letters(groups[0]+groups[1]) {
+		return errors.New("heer indicator must be two groups of three letters")
+	}
+	if m.Kenngruppe != "" && (len(m.Kenngruppe) != 5 || !letters(m.Kenngruppe)) {
+		return errors.New("kenngruppe must be five letters")
+	}
+	return nil
+}
+
+// Format sets out the Message for sending in the Layout, wrapping the text after groupsPerLine groups.
+// The letter count in the header is the number of letters in the text as sent, including the Kenngruppe or indicator groups.
+//
+// e.g. in the Heer layout
+//
+//	U6Z DE C 1510 = 49 = EHZ TBS =
+//	TVEXS QBLTW LDAHH YEOEF PTWYB LENDP MKOXL DFAMU DWIJD XRJZ
+//
+// # Errors
+//
+// An error is returned if a detail needed by the Layout is missing or badly formed, or the text is not letters A-Z.
+func (m Message) Format(layout Layout, groupsPerLine int) (string, error) {
+	if err := m.check(layout); err != nil {
+		return "", err
+	}
+	switch layout {
+	case LayoutHeer:
+		groups := util.SplitGroups(m.Text, layout.groupSize())
+		if m.Kenngruppe != "" {
+			groups = append([]string{m.Kenngruppe}, groups...)
+		}
+		header := fmt.Sprintf("%s DE %s %s = %d = %s =",
+			m.To, m.From, m.Time, len(m.Kenngruppe)+len(m.Text), strings.Join(strings.Fields(m.Indicator), " "))
+		return header + "\n" + util.JoinGroups(groups, groupsPerLine), nil
+	case LayoutKriegsmarine:
+		// The indicator group is kept whole at the end even when the text does not fill its last group.
+		groups := append([]string{m.Indicator}, util.SplitGroups(m.Text, layout.groupSize())...)
+		groups = append(groups, m.Indicator)
+		header := fmt.Sprintf("%s %s = %d =", m.From, m.Time, len(m.Text)+2*len(m.Indicator))
+		return header + "\n" + util.JoinGroups(groups, groupsPerLine), nil
+	default:
+		return FormatGroups(m.Text, layout.groupSize(), groupsPerLine), nil
+	}
+}
+
+// ParseMessage reads a Message set out by Format, as an intercept would be taken down, so that its text can be decrypted.
+// The Layout is recognised from the header: one with "DE" between call signs is Heer, any other is Kriegsmarine,
+// and text with no header is taken to be groups alone. Case, spacing and line breaks in the text are ignored,
+// as is a closing '='.
+//
+// The Kenngruppe cannot be told from the text it begins, so the first group of a Heer message is only taken as the
+// Kenngruppe if kenngruppe is set.
+//
+// # Errors
+//
+// An error is returned if the header is badly formed, the letter count does not match the text,
+// or the Kriegsmarine indicator group is not repeated at the end of the text.
+func ParseMessage(text string, kenngruppe bool) (Message, Layout, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	header, body, found := strings.Cut(text, "\n")
+	if !found || !strings.Contains(header, "=") {
+		header, body = "", text
+	}
+	body = strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(body), "=")), "")
+	if !letters(body) {
+		return Message{}, LayoutGroups, errors.New("message text must only contain letters")
+	}
+	if header == "" {
+		return Message{Text: body}, LayoutGroups, nil
+	}
+
+	parts := strings.Split(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(header), "=")), "=")
+	for idx := range parts {
+		parts[idx] = strings.TrimSpace(parts[idx])
+	}
+	if len(parts) < 2 {
+		return Message{}, LayoutGroups, errors.New("message header must give the letter count between '=' signs")
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Message{}, LayoutGroups, errors.New("message header letter count must be a number")
+	}
+	if count != len(body) {
+		return Message{}, LayoutGroups, fmt.Errorf("message header gives %d letters but the text has %d", count, len(body))
+	}
+
+	origin := strings.Fields(parts[0])
+	if len(origin) == 4 && origin[1] == "DE" {
+		if len(parts) != 3 {
+			return Message{}, LayoutHeer, errors.New("heer header must give the indicator groups after the letter count")
+		}
+		message := Message{To: origin[0], From: origin[2], Time: origin[3], Indicator: parts[2], Text: body}
+		if kenngruppe {
+			if len(body) < 5 {
+				return Message{}, LayoutHeer, errors.New("message text is too short to begin with a kenngruppe")
+			}
+			message.Kenngruppe, message.Text = body[:5], body[5:]
+		}
+		return message, LayoutHeer, message.check(LayoutHeer)
+	}
+
+	if len(origin) != 2 || len(parts) != 2 {
+		return Message{}, LayoutKriegsmarine, errors.New("message header must be 'TO DE FROM TIME = COUNT = IND IND =' or 'FROM TIME = COUNT ='")
+	}
+	if len(body) < 8 || body[:4] != body[len(body)-4:] {
+		return Message{}, LayoutKriegsmarine, errors.New("kriegsmarine indicator group must begin and end the text")
+	}
+	message := Message{From: origin[0], Time: origin[1], Indicator: body[:4], Text: body[4 : len(body)-4]}
+	return message, LayoutKriegsmarine, message.check(LayoutKriegsmarine)
+}
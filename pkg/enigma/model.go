@@ -0,0 +1,160 @@
+package enigma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Model describes which rotors and reflectors an Enigma model was issued with, and where they could be fitted.
+// Rotors and reflectors are identified by the Name given to them in the standard RotorSet.
+//
+// MaxPlugs is the number of plugboard cables issued with the machine.
+// FourthRotors is empty for models without a fourth rotor slot.
+type Model struct {
+	Name         string
+	Rotors       []string
+	FourthRotors []string
+	Reflectors   []string
+	MaxPlugs     int
+}
+
+// EnigmaI returns the Model of the Enigma I used by the German Army and Air Force, with rotors I-V and reflectors A-C.
+func EnigmaI() Model {
+	return Model{
+		Name:       "I",
+		Rotors:     []string{"I", "II", "III", "IV", "V"},
+		Reflectors: []string{"UKW-A", "UKW-B", "UKW-C"},
+		MaxPlugs:   10,
+	}
+}
+
+// EnigmaM3 returns the Model of the naval Enigma M3, which added rotors VI-VIII to those of the Enigma I.
+func EnigmaM3() Model {
+	return Model{
+		Name:       "M3",
+		Rotors:     []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"},
+		Reflectors: []string{"UKW-B", "UKW-C"},
+		MaxPlugs:   10,
+	}
+}
+
+// EnigmaM4 returns the Model of the naval Enigma M4.
+// Its fourth rotor, Beta or Gamma, sits beside one of the thin reflectors b or c, which left room for it.
+func EnigmaM4() Model {
+	return Model{
+		Name:         "M4",
+		Rotors:       []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"},
+		FourthRotors: []string{"Beta", "Gamma"},
+		Reflectors:   []string{"Narrow UKW-b", "Narrow UKW-c"},
+		MaxPlugs:     10,
+	}
+}
+
+// EnigmaZ30 returns the Model of the Enigma Z30, which enciphers the digits 0-9 and has no plugboard.
+func EnigmaZ30() Model {
+	return Model{
+		Name:       "Z30",
+		Rotors:     []string{"Z30-I", "Z30-II", "Z30-III"},
+		Reflectors: []string{"Z30-UKW"},
+		MaxPlugs:   0,
+	}
+}
+
+// ModelNames returns the names accepted by NewModel.
+func ModelNames() []string {
+	return []string{"I", "M3", "M4", "Z30"}
+}
+
+// NewModel returns the Model with the given name, which is one of ModelNames.
+//
+// # Errors
+//
+// An error is returned if the name is not a known model.
+func NewModel(name string) (Model, error) {
+	switch strings.ToUpper(name) {
+	case "I":
+		return EnigmaI(), nil
+	case "M3":
+		return EnigmaM3(), nil
+	case "M4":
+		return EnigmaM4(), nil
+	case "Z30":
+		return EnigmaZ30(), nil
+	default:
+		return Model{}, fmt.Errorf("unknown enigma model %s, must be one of %s", name, strings.Join(ModelNames(), "|"))
+	}
+}
+
+// A ConfigProblem is a way in which a machine's setup could not have been made on a Model.
+type ConfigProblem struct {
+	Part   string
+	Detail string
+}
+
+// Error returns the problem as a single line of text, so a ConfigProblem may be used as an error.
+func (p ConfigProblem) Error() string {
+	return fmt.Sprintf("%s: %s", p.Part, p.Detail)
+}
+
+// contains returns whether a name is in a list of names.
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns every problem with setting up the machine on the Model, so that all of them can be reported at once.
+// It checks that each slot holds a rotor the Model was issued with and that may be fitted in that slot,
+// that no rotor is used twice, that the reflector suits the Model and the plugboard does not use more cables than were issued.
+// No problems are returned if the setup is possible.
+func (m Model) Check(machine Enigma, useFourthRotor bool) []ConfigProblem {
+	problems := []ConfigProblem{}
+
+	slots := []struct {
+		part  string
+		rotor Rotor
+	}{
+		{"left rotor", machine.LeftRotor},
+		{"center rotor", machine.CenterRotor},
+		{"right rotor", machine.RightRotor},
+	}
+	used := make(map[string]string)
+	for _, slot := range slots {
+		name := slot.rotor.Name
+		switch {
+		case contains(EnigmaM4().FourthRotors, name):
+			problems = append(problems, ConfigProblem{slot.part, fmt.Sprintf("rotor %s can only be used as the fourth rotor", name)})
+		case !contains(m.Rotors, name):
+			problems = append(problems, ConfigProblem{slot.part, fmt.Sprintf("rotor %s was not issued with the Enigma %s", name, m.Name)})
+		}
+		if other, exists := used[name]; exists {
+			problems = append(problems, ConfigProblem{slot.part, fmt.Sprintf("rotor %s is already used as the %s", name, other)})
+		} else {
+			used[name] = slot.part
+		}
+	}
+
+	switch {
+	case useFourthRotor && len(m.FourthRotors) == 0:
+		problems = append(problems, ConfigProblem{"fourth rotor", fmt.Sprintf("the Enigma %s has no fourth rotor slot", m.Name)})
+	case useFourthRotor && !contains(m.FourthRotors, machine.FourthRotor.Name):
+		problems = append(problems, ConfigProblem{"fourth rotor", fmt.Sprintf("rotor %s cannot be used as the fourth rotor, it must be one of %s",
+			machine.FourthRotor.Name, strings.Join(m.FourthRotors, "|"))})
+	case !useFourthRotor && len(m.FourthRotors) > 0:
+		problems = append(problems, ConfigProblem{"fourth rotor", fmt.Sprintf("the Enigma %s needs a fourth rotor", m.Name)})
+	}
+
+	if !contains(m.Reflectors, machine.Reflector.Name) {
+		problems = append(problems, ConfigProblem{"reflector", fmt.Sprintf("reflector %s cannot be used with the Enigma %s, it must be one of %s",
+			machine.Reflector.Name, m.Name, strings.Join(m.Reflectors, "|"))})
+	}
+
+	if plugs := machine.Plugs.PlugCount(); plugs > m.MaxPlugs {
+		problems = append(problems, ConfigProblem{"plugboard", fmt.Sprintf("%d plugs are used but the Enigma %s was issued with %d cables",
+			plugs, m.Name, m.MaxPlugs)})
+	}
+	return problems
+}
@@ -1,19 +1,26 @@
 package enigma
 
 import (
-	"EnigmaLorenz/pkg/util"
 	"log"
 )
 
 // A Plugboard contains the state of all the mapping between letters.
+// The symbols that may be plugged together are those of the Plugboard's Alphabet.
 type Plugboard struct {
-	state map[byte]byte
+	state    map[byte]byte
+	alphabet Alphabet
 }
 
-// NewPlugboard is a constructor, returning an empty Plugboard
+// NewPlugboard is a constructor, returning an empty Plugboard for the letters A-Z
 func NewPlugboard() Plugboard {
+	return NewPlugboardFor(LatinAlphabet())
+}
+
+// NewPlugboardFor returns an empty Plugboard for the symbols of an Alphabet.
+func NewPlugboardFor(alphabet Alphabet) Plugboard {
 	return Plugboard{
-		state: make(map[byte]byte),
+		state:    make(map[byte]byte),
+		alphabet: alphabet,
 	}
 }
 
@@ -21,11 +28,11 @@ func NewPlugboard() Plugboard {
 //
 // # Errors
 //
-// letter 1 and letter 2 must be ASCII characters between A(65) - Z(90).
+// letter 1 and letter 2 must be symbols of the Plugboard's Alphabet, ASCII characters between A(65) - Z(90) by default.
 // A fatal error will occur if the characters are invalid or a mapping already exists for one of the characters.
 func (p *Plugboard) AddPlug(letter1 byte, letter2 byte) {
-	if !(util.ValidChars(string(letter1), false) && util.ValidChars(string(letter2), false)) {
-		log.Fatal("Invalid characters given to plugboard, must be A-Z")
+	if !p.alphabet.Valid(string([]byte{letter1, letter2})) {
+		log.Fatalf("Invalid characters given to plugboard, must be one of %s", p.alphabet.Symbols())
 	}
 	_, Ok := p.state[letter1]
 	if Ok {
@@ -61,3 +68,8 @@ func (p *Plugboard) Translate(letter byte) byte {
 	}
 	return val
 }
+
+// PlugCount returns the number of plugs on the Plugboard, each joining two symbols.
+func (p *Plugboard) PlugCount() int {
+	return len(p.state) / 2
+}
@@ -34,43 +34,56 @@ func indexOf(needle byte, haystack []byte) (byte, error) {
 // TurnoverList contains the list of values that will be the current value when notch is aligned with the mechanism.
 // When a value in the turnover list is the current position,
 // the Enigma Encrypt function will step the rotor to its left on the next rotation.
+//
+// Alphabet gives the symbols the rotor's contacts are lettered with, and is set by NewRotor.
+// Left as the zero value it is the 26 letter Alphabet A-Z.
 type Rotor struct {
 	Name         string
 	Wires        []byte
 	shownPos     byte
 	TurnoverList []byte
 	ringSetting  byte
+	Alphabet     Alphabet
+	reversed     bool
+}
+
+// positions returns the number of positions on the rotor, taking a rotor with no wiring to have the standard 26.
+func (r *Rotor) positions() int {
+	if len(r.Wires) == 0 {
+		return len(latinLetters)
+	}
+	return len(r.Wires)
 }
 
 // SetShownPos is analogous to setting the rotor position by changing the letter/number shown in the window.
-// The position can be any number between 1 and 26 inclusively.
+// The position can be any number between 1 and the number of positions on the rotor (26 for standard rotors) inclusively.
 //
 // # Errors
 //
-// A fatal error will occur if a value less than 0 or more than 26 is passed in.
+// A fatal error will occur if a value less than 1 or more than the number of positions is passed in.
 func (r *Rotor) SetShownPos(pos byte) {
-	if pos < 1 || pos > 26 {
-		log.Fatal("Rotor position must be set to value between 1 and 26")
+	if pos < 1 || int(pos) > r.positions() {
+		log.Fatalf("Rotor position must be set to value between 1 and %d", r.positions())
 	}
 	r.shownPos = pos - 1
 }
 
 // GetShownPos will return the value that would be showing through the Enigma window.
-// The value returned will be a value between 1 and 26 inclusively.
+// The value returned will be a value between 1 and the number of positions on the rotor inclusively.
 func (r *Rotor) GetShownPos() byte {
 	return r.shownPos + 1
 }
 
 // SetRingSetting is equivalent to the ring setting on a real Enigma.
 // It provides an offset between the input wiring and output wiring, achieved by rotating one side of the rotor wiring.
-// The offset can be any number between 0 and 25 inclusively.
+// The offset can be any number between 0 and one less than the number of positions on the rotor (25 for standard rotors) inclusively.
 //
 // # Errors
 //
-// A fatal error will occur if a value less than 0 or greater than 25 is passed as a parameter.
+// A fatal error will occur if a value less than 0 or not less than the number of positions is passed as a parameter.
 func (r *Rotor) SetRingSetting(offset byte) {
-	if offset < 0 || offset > 25 {
-		log.Fatal("Rotor position must be set to value between 0 and 25")
+	if int(offset) >= r.positions() {
+		log.Fatalf("Rotor position must be set to value between 0 and %d", r.positions()-1)
 	}
 	r.ringSetting = offset
 }
@@ -96,6 +109,11 @@ func (r *Rotor) Rotate() {
 	r.shownPos %= byte(len(r.Wires))
 }
 
+// RotateBackwards will rotate the rotor clockwise by one step, undoing Rotate.
+func (r *Rotor) RotateBackwards() {
+	r.shownPos = byte((int(r.shownPos) + len(r.Wires) - 1) % len(r.Wires))
+}
+
 // Translate will return the output signal from a given input signal.
 func (r Rotor) Translate(plain byte) byte {
 
@@ -112,6 +130,30 @@ func (r Rotor) TranslateReverse(cipher byte) byte {
 	return byte(shiftedPlain)
 }
 
+// Reversed returns the rotor with its wiring turned round, as if the wired core were inserted back to front.
+// Turning the core round swaps its two faces and mirrors the contacts on each face.
+// The notches and settings are unchanged and an R is appended to the name.
+// Reversing a rotor twice gives back the original wiring.
+func (r Rotor) Reversed() Rotor {
+	n := len(r.Wires)
+	wires := make([]byte, n)
+	for in, out := range r.Wires {
+		wires[(n-int(out))%n] = byte((n - in) % n)
+	}
+	reversed := r
+	reversed.Name = r.Name + "R"
+	reversed.Wires = wires
+	reversed.TurnoverList = append([]byte{}, r.TurnoverList...)
+	reversed.reversed = !r.reversed
+	return reversed
+}
+
+// IsReversed returns whether the rotor has been turned round by Reversed, an odd number of times.
+// Machines whose rotors turn the other way when inserted back to front use this to step them backwards.
+func (r *Rotor) IsReversed() bool {
+	return r.reversed
+}
+
 // RotorSet contains all the standard rotors and reflectors that were available from Enigma 1 to M4 Enigma.
 // The rotors were gathered from [Crypto Museum]: https://www.cryptomuseum.com/crypto/enigma/m4/index.htm#wiring
 //
@@ -0,0 +1,117 @@
+package enigma
+
+import (
+	"errors"
+	"log"
+)
+
+// stepsToNotch returns the number of rotations needed before the rotor is next at a notch.
+// If the rotor has no notches then the number of positions on the rotor is returned.
+func (r *Rotor) stepsToNotch() int {
+	size := len(r.Wires)
+	steps := size
+	for _, notch := range r.TurnoverList {
+		if distance := (int(notch) - int(r.shownPos) + size) % size; distance < steps {
+			steps = distance
+		}
+	}
+	return steps
+}
+
+// advance rotates the rotor counterclockwise by the given number of steps.
+func (r *Rotor) advance(steps int) {
+	r.shownPos = byte((int(r.shownPos) + steps) % len(r.Wires))
+}
+
+// Seek moves the rotors on to where they would be after n more key presses, without enciphering anything.
+// This allows decryption to start from the middle of a message after setting the message key.
+//
+// Only the right rotor moves between the key presses where the center or left rotors step,
+// so the right rotor is advanced straight to each of its notches rather than being stepped n times.
+//
+// # Errors
+//
+// A fatal error will occur if n is negative.
+func (machine *Enigma) Seek(n int) {
+	if n < 0 {
+		log.Fatal("Enigma can only seek forwards, use StepBack to move backwards")
+	}
+	for n > 0 {
+		if machine.CenterRotor.AtNotch() {
+			machine.step()
+			n--
+			continue
+		}
+
+		plain := machine.RightRotor.stepsToNotch()
+		if plain >= n {
+			machine.RightRotor.advance(n)
+			return
+		}
+		machine.RightRotor.advance(plain)
+		n -= plain
+
+		machine.step()
+		n--
+	}
+}
+
+// rotorPositions holds the shown positions of the three moving rotors.
+type rotorPositions struct {
+	left, center, right byte
+}
+
+// predecessors returns every set of rotor positions that a key press would move on to the given positions.
+// Because of the double step of the center rotor there can be more than one.
+func (machine *Enigma) predecessors(after rotorPositions) []rotorPositions {
+	size := len(machine.RightRotor.Wires)
+	back := func(pos byte, steps int) byte {
+		return byte((int(pos) - steps + 2*size) % size)
+	}
+
+	right := back(after.right, 1)
+	rightStep := 0
+	if _, err := indexOf(right, machine.RightRotor.TurnoverList); err == nil {
+		rightStep = 1
+	}
+
+	candidates := []rotorPositions{}
+	for _, doubleStep := range []int{0, 1} {
+		center := back(after.center, rightStep+doubleStep)
+		_, err := indexOf(center, machine.CenterRotor.TurnoverList)
+		if (err == nil) == (doubleStep == 1) {
+			candidates = append(candidates, rotorPositions{back(after.left, doubleStep), center, right})
+		}
+	}
+	return candidates
+}
+
+// StepBack moves the rotors back to where they were before the last key press, undoing it.
+//
+// The double step means two different positions can be moved on to the same position.
+// When this happens the position that could itself have been reached by a key press is chosen.
+//
+// # Errors
+//
+// An error is returned if no key press could have led to the current position,
+// as happens when the center rotor has been set directly onto its notch.
+func (machine *Enigma) StepBack() error {
+	current := rotorPositions{machine.LeftRotor.shownPos, machine.CenterRotor.shownPos, machine.RightRotor.shownPos}
+	candidates := machine.predecessors(current)
+	if len(candidates) == 0 {
+		return errors.New("rotor positions cannot be reached by a key press")
+	}
+
+	previous := candidates[0]
+	for _, candidate := range candidates {
+		if len(machine.predecessors(candidate)) > 0 {
+			previous = candidate
+			break
+		}
+	}
+
+	machine.LeftRotor.shownPos = previous.left
+	machine.CenterRotor.shownPos = previous.center
+	machine.RightRotor.shownPos = previous.right
+	return nil
+}
@@ -0,0 +1,150 @@
+package enigma
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// contactName returns how a contact is shown in errors, as a letter for a 26 contact rotor and as its number otherwise.
+func contactName(contact int, size int) string {
+	if size == len(latinLetters) && contact >= 0 && contact < size {
+		return string(latinLetters[contact])
+	}
+	return strconv.Itoa(contact)
+}
+
+// Validate checks that the Rotor can be used, as a badly wired rotor otherwise gives garbage without any error.
+//
+// # Errors
+//
+// An error naming the rotor and the contacts at fault is returned if:
+//   - The rotor has no wires
+//   - A wire goes to a contact that is not on the rotor
+//   - Two contacts are wired to the same contact, so that Wires is not a permutation
+//   - A turnover position is not on the rotor, or is given twice
+//   - The shown position or ring setting is not on the rotor
+func (r Rotor) Validate() error {
+	size := len(r.Wires)
+	if size == 0 {
+		return fmt.Errorf("rotor %s has no wires", r.Name)
+	}
+
+	wiredFrom := make([]int, size)
+	for idx := range wiredFrom {
+		wiredFrom[idx] = -1
+	}
+	for in, out := range r.Wires {
+		if int(out) >= size {
+			return fmt.Errorf("rotor %s wires contact %s to %d, which is not one of its %d contacts",
+				r.Name, contactName(in, size), out, size)
+		}
+		if first := wiredFrom[out]; first != -1 {
+			return fmt.Errorf("rotor %s wires contacts %s and %s both to %s",
+				r.Name, contactName(first, size), contactName(in, size), contactName(int(out), size))
+		}
+		wiredFrom[out] = in
+	}
+
+	seen := make(map[byte]bool)
+	for _, notch := range r.TurnoverList {
+		if int(notch) >= size {
+			return fmt.Errorf("rotor %s has turnover position %d, which is not one of its %d positions", r.Name, notch, size)
+		}
+		if seen[notch] {
+			return fmt.Errorf("rotor %s has turnover position %s more than once", r.Name, contactName(int(notch), size))
+		}
+		seen[notch] = true
+	}
+
+	if int(r.shownPos) >= size {
+		return fmt.Errorf("rotor %s is shown at position %d but has %d positions", r.Name, r.shownPos+1, size)
+	}
+	if int(r.ringSetting) >= size {
+		return fmt.Errorf("rotor %s has ring setting %d but has %d positions", r.Name, r.ringSetting, size)
+	}
+	return nil
+}
+
+// ValidateReflector checks that the Rotor can be used as a reflector.
+// As well as being a valid Rotor, a reflector must connect its contacts together in pairs,
+// never wire a contact to itself, and have no notches as it never turns.
+//
+// # Errors
+//
+// An error naming the reflector and the contacts at fault is returned if any of these do not hold.
+func (r Rotor) ValidateReflector() error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	size := len(r.Wires)
+	if len(r.TurnoverList) > 0 {
+		return fmt.Errorf("reflector %s cannot have notches", r.Name)
+	}
+	for in, out := range r.Wires {
+		if int(out) == in {
+			return fmt.Errorf("reflector %s wires contact %s to itself", r.Name, contactName(in, size))
+		}
+		if back := int(r.Wires[out]); back != in {
+			return fmt.Errorf("reflector %s wires %s to %s but %s back to %s",
+				r.Name, contactName(in, size), contactName(int(out), size), contactName(int(out), size), contactName(back, size))
+		}
+	}
+	return nil
+}
+
+// Validate checks every rotor in the RotorSet with Rotor.Validate, and every reflector with Rotor.ValidateReflector.
+//
+// # Errors
+//
+// The error for the first rotor or reflector found to be invalid is returned.
+func (set RotorSet) Validate() error {
+	for _, rotor := range []Rotor{set.I, set.II, set.III, set.IV, set.V, set.VI, set.VII, set.VIII, set.Beta, set.Gamma} {
+		if err := rotor.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, reflector := range []Rotor{set.UKW_A, set.UKW_B, set.UKW_C, set.UKW_b, set.UKW_c} {
+		if err := reflector.ValidateReflector(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that every plug on the Plugboard joins two different symbols of its Alphabet in both directions.
+//
+// # Errors
+//
+// An error naming the symbols at fault is returned if a symbol is not in the Alphabet, is plugged to itself,
+// or is plugged to a symbol that is not plugged back to it.
+func (p *Plugboard) Validate() error {
+	for from, to := range p.state {
+		if !p.alphabet.Valid(string([]byte{from, to})) {
+			return fmt.Errorf("plug %c:%c must only join symbols of %s", from, to, p.alphabet.Symbols())
+		}
+		if from == to {
+			return fmt.Errorf("plug joins %c to itself", from)
+		}
+		if back, exists := p.state[to]; !exists || back != from {
+			return fmt.Errorf("plug joins %c to %c but %c is not joined back to %c", from, to, to, from)
+		}
+	}
+	return nil
+}
+
+// validate checks the rotors, reflector and plugboard the machine will use.
+func (machine *Enigma) validate(useFourthRotor bool) error {
+	path := []Rotor{machine.RightRotor, machine.CenterRotor, machine.LeftRotor}
+	if useFourthRotor {
+		path = append(path, machine.FourthRotor)
+	}
+	for _, rotor := range path {
+		if err := rotor.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := machine.Reflector.ValidateReflector(); err != nil {
+		return err
+	}
+	return machine.Plugs.Validate()
+}
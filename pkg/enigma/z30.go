@@ -0,0 +1,30 @@
+package enigma
+
+import (
+	_ "embed"
+)
+
+//go:embed z30.yaml
+var z30Catalog string
+
+// Z30RotorSet contains rotors for a 10 digit Enigma in the style of the Enigma Z30,
+// a version of the Enigma with a keyboard of only the digits 0-9 and 10 position rotors.
+// The rotors are lettered with DigitAlphabet and have a single notch at 9.
+type Z30RotorSet struct {
+	I   Rotor
+	II  Rotor
+	III Rotor
+	UKW Rotor
+}
+
+// GenerateZ30Rotors returns the Z30RotorSet held in z30.yaml, with the rotors named Z30-I to Z30-III and the reflector Z30-UKW.
+func GenerateZ30Rotors() Z30RotorSet {
+	registry := NewRegistry()
+	registry.MustLoad(z30Catalog)
+	return Z30RotorSet{
+		I:   registry.MustRotor("Z30-I"),
+		II:  registry.MustRotor("Z30-II"),
+		III: registry.MustRotor("Z30-III"),
+		UKW: registry.MustReflector("Z30-UKW"),
+	}
+}
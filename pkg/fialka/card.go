@@ -0,0 +1,101 @@
+package fialka
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Card is the punched card read by the Fialka's card reader, wiring each key to a contact of the first rotor.
+// Entry[k] gives the contact that key k is connected to.
+type Card struct {
+	Entry [Contacts]byte
+}
+
+// IdentityCard returns a Card connecting every key to the contact of the same number.
+func IdentityCard() Card {
+	card := Card{}
+	for idx := range card.Entry {
+		card.Entry[idx] = byte(idx)
+	}
+	return card
+}
+
+// in returns the rotor contact for a key.
+func (c *Card) in(key byte) byte {
+	return c.Entry[key]
+}
+
+// out returns the key for a rotor contact.
+func (c *Card) out(contact byte) byte {
+	for key, entry := range c.Entry {
+		if entry == contact {
+			return byte(key)
+		}
+	}
+	return contact
+}
+
+// Format writes out the Card as it is punched: 30 rows, one per key, each with a single hole (O) among 30 columns (.),
+// the column giving the contact the key is connected to.
+func (c Card) Format() string {
+	var text strings.Builder
+	for _, entry := range c.Entry {
+		row := []byte(strings.Repeat(".", Contacts))
+		row[entry] = 'O'
+		text.Write(row)
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
+
+// ParseCard reads a Card written by Format. A hole may be written as 'O' or 'X' and an unpunched position as '.'.
+// Blank lines and lines starting with '#' are ignored.
+//
+// # Errors
+//
+// An error is returned if there are not 30 rows of 30 columns, a row does not have exactly one hole,
+// or two keys are connected to the same contact.
+func ParseCard(text string) (Card, error) {
+	card := Card{}
+	rows := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if len(rows) != Contacts {
+		return card, fmt.Errorf("card must have %d rows", Contacts)
+	}
+
+	used := [Contacts]bool{}
+	for key, row := range rows {
+		if len(row) != Contacts {
+			return card, fmt.Errorf("card row %d must have %d columns", key+1, Contacts)
+		}
+		hole := -1
+		for col, position := range row {
+			switch position {
+			case 'O', 'o', 'X', 'x':
+				if hole != -1 {
+					return card, fmt.Errorf("card row %d has more than one hole", key+1)
+				}
+				hole = col
+			case '.':
+			default:
+				return card, errors.New("card rows may only contain holes (O|X) and blanks (.)")
+			}
+		}
+		if hole == -1 {
+			return card, fmt.Errorf("card row %d has no hole", key+1)
+		}
+		if used[hole] {
+			return card, fmt.Errorf("card connects two keys to contact %d", hole+1)
+		}
+		used[hole] = true
+		card.Entry[key] = byte(hole)
+	}
+	return card, nil
+}
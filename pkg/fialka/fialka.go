@@ -0,0 +1,96 @@
+// Package fialka simulates the Soviet Fialka M-125, a rotor machine of the Warsaw Pact.
+//
+// The Fialka passes each key through a punched card, ten rotors and a reflector, then back through the rotors and card,
+// so like the Enigma it is reciprocal. The rotors are enigma.Rotor values with 30 contacts,
+// and any rotor core may be placed reversed using enigma.Rotor.Reversed.
+// Neighbouring rotors step in opposite directions, each carried on by raised blocking pins on the rotor before it.
+package fialka
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"fmt"
+)
+
+// A Fialka is the representation of the card, the ten rotors, the reflector and the keyboard mode.
+// Rotors are listed in the order the signal first reaches them from the card.
+type Fialka struct {
+	Rotors    [10]enigma.Rotor
+	Reflector enigma.Rotor
+	Card      Card
+	Keyboard  Keyboard
+}
+
+// step advances the rotors before a key is enciphered.
+// The first rotor always steps, and each other rotor steps when the rotor before it has a raised blocking pin
+// at the sensing position. Rotors 1, 3, 5, 7 and 9 step forwards and the others step backwards.
+func (machine *Fialka) step() {
+	var raised [10]bool
+	for idx := range machine.Rotors {
+		raised[idx] = machine.Rotors[idx].AtNotch()
+	}
+	for idx := range machine.Rotors {
+		if idx != 0 && !raised[idx-1] {
+			continue
+		}
+		rotor := &machine.Rotors[idx]
+		if idx%2 == 0 {
+			rotor.Rotate()
+		} else {
+			rotor.RotateBackwards()
+		}
+	}
+}
+
+// reflect passes a contact through the reflector, using the fixup circuit to join contacts wired to themselves in pairs.
+func (machine *Fialka) reflect(contact byte) byte {
+	out := machine.Reflector.Translate(contact)
+	if out != contact {
+		return out
+	}
+	self := []byte{}
+	for idx := range machine.Reflector.Wires {
+		if machine.Reflector.Translate(byte(idx)) == byte(idx) {
+			self = append(self, byte(idx))
+		}
+	}
+	for idx := range self {
+		if self[idx] == contact {
+			return self[idx^1]
+		}
+	}
+	return out
+}
+
+// Encrypt enciphers text typed in the Fialka's keyboard mode.
+// As the machine is reciprocal, deciphering is done by enciphering the ciphertext from the same start.
+//
+// # Errors
+//
+// An error is returned if the text holds a symbol that cannot be typed in the keyboard mode,
+// or a rotor or the reflector does not have 30 contacts.
+func (machine *Fialka) Encrypt(text string) (string, error) {
+	for _, rotor := range append(machine.Rotors[:], machine.Reflector) {
+		if len(rotor.Wires) != Contacts {
+			return "", fmt.Errorf("rotor %s must have %d contacts", rotor.Name, Contacts)
+		}
+	}
+	keys, err := machine.Keyboard.Keys(text)
+	if err != nil {
+		return "", err
+	}
+
+	for idx, key := range keys {
+		machine.step()
+
+		contact := machine.Card.in(key)
+		for _, rotor := range machine.Rotors {
+			contact = rotor.Translate(contact)
+		}
+		contact = machine.reflect(contact)
+		for rotorIndex := len(machine.Rotors) - 1; rotorIndex >= 0; rotorIndex-- {
+			contact = machine.Rotors[rotorIndex].TranslateReverse(contact)
+		}
+		keys[idx] = machine.Card.out(contact)
+	}
+	return machine.Keyboard.Text(keys)
+}
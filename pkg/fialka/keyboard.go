@@ -0,0 +1,104 @@
+package fialka
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Contacts is the number of keys on the keyboard and contacts on every rotor, card row and the reflector.
+const Contacts = 30
+
+// A Keyboard maps the 30 keys of the Fialka to the symbols typed in one of its keyboard modes.
+type Keyboard struct {
+	Name    string
+	symbols []rune
+	folds   map[rune]rune
+}
+
+// CyrillicKeyboard returns the Cyrillic keyboard mode.
+// The 33 letter Russian alphabet is typed on 30 keys, with Ё typed as Е, Й as И and Ъ as Ь.
+func CyrillicKeyboard() Keyboard {
+	return Keyboard{
+		Name:    "cyrillic",
+		symbols: []rune("АБВГДЕЖЗИКЛМНОПРСТУФХЦЧШЩЫЬЭЮЯ"),
+		folds:   map[rune]rune{'Ё': 'Е', 'Й': 'И', 'Ъ': 'Ь'},
+	}
+}
+
+// LatinKeyboard returns the Latin keyboard mode, with the letters A-Z on the first 26 keys
+// and the remaining four keys typing the punctuation . , - and /.
+func LatinKeyboard() Keyboard {
+	return Keyboard{
+		Name:    "latin",
+		symbols: []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ.,-/"),
+		folds:   map[rune]rune{},
+	}
+}
+
+// KeyboardNames returns the name of every keyboard mode accepted by NewKeyboard.
+func KeyboardNames() []string {
+	return []string{"cyrillic", "latin"}
+}
+
+// NewKeyboard returns the keyboard mode with the given name.
+//
+// # Errors
+//
+// An error is returned if the name is not a known keyboard mode.
+func NewKeyboard(name string) (Keyboard, error) {
+	switch name {
+	case "cyrillic":
+		return CyrillicKeyboard(), nil
+	case "latin":
+		return LatinKeyboard(), nil
+	default:
+		return Keyboard{}, fmt.Errorf("keyboard mode must be one of %s", strings.Join(KeyboardNames(), ", "))
+	}
+}
+
+// Symbols returns the symbol on each key in order.
+func (k Keyboard) Symbols() string {
+	return string(k.symbols)
+}
+
+// Keys converts text into the key (0-29) for each symbol, after folding letters without a key of their own.
+//
+// # Errors
+//
+// An error is returned if a symbol cannot be typed in the keyboard mode.
+func (k Keyboard) Keys(text string) ([]byte, error) {
+	keys := []byte{}
+	for _, symbol := range text {
+		if folded, exists := k.folds[symbol]; exists {
+			symbol = folded
+		}
+		key := -1
+		for idx, keySymbol := range k.symbols {
+			if keySymbol == symbol {
+				key = idx
+			}
+		}
+		if key == -1 {
+			return []byte{}, fmt.Errorf("symbol %c cannot be typed in %s mode", symbol, k.Name)
+		}
+		keys = append(keys, byte(key))
+	}
+	return keys, nil
+}
+
+// Text converts keys (0-29) back into the symbols printed for them.
+//
+// # Errors
+//
+// An error is returned if a key is not on the keyboard.
+func (k Keyboard) Text(keys []byte) (string, error) {
+	var text strings.Builder
+	for _, key := range keys {
+		if int(key) >= len(k.symbols) {
+			return "", errors.New("key is not on the keyboard")
+		}
+		text.WriteRune(k.symbols[key])
+	}
+	return text.String(), nil
+}
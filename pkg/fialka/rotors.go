@@ -0,0 +1,66 @@
+package fialka
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	_ "embed"
+	"errors"
+	"fmt"
+)
+
+//go:embed rotors.yaml
+var rotorCatalog string
+
+// rotorRegistry returns a Registry holding the rotors and reflector wiring in rotors.yaml.
+func rotorRegistry() *enigma.Registry {
+	registry := enigma.NewRegistry()
+	registry.MustLoad(rotorCatalog)
+	return registry
+}
+
+// GenerateRotors returns the ten rotors held in rotors.yaml, named 1-10, each with a core of 30 contacts
+// and a ring of blocking pins marked by its TurnoverList.
+func GenerateRotors() [10]enigma.Rotor {
+	var rotors [10]enigma.Rotor
+	registry := rotorRegistry()
+	for idx := range rotors {
+		rotors[idx] = registry.MustRotor(fmt.Sprintf("%d", idx+1))
+	}
+	return rotors
+}
+
+// NewReflector creates the reflector from its wiring, which must connect contacts in pairs.
+// Unlike the Enigma reflector, the Fialka reflector may wire a contact to itself, which would let a key encipher to itself.
+// The machine's fixup circuit joins such contacts to each other in pairs, so there must be an even number of them.
+//
+// # Errors
+//
+// An error is returned if the wiring does not have 30 contacts, does not connect contacts in pairs,
+// or has an odd number of contacts wired to themselves.
+func NewReflector(wires []byte) (enigma.Rotor, error) {
+	reflector := enigma.Rotor{Name: "Reflector", Wires: wires}
+	if len(wires) != Contacts {
+		return reflector, fmt.Errorf("reflector must have %d contacts", Contacts)
+	}
+	self := 0
+	for contact, wire := range wires {
+		if int(wire) >= Contacts || int(wires[wire]) != contact {
+			return reflector, errors.New("reflector must connect contacts in pairs")
+		}
+		if int(wire) == contact {
+			self++
+		}
+	}
+	if self%2 != 0 {
+		return reflector, errors.New("reflector must have an even number of contacts wired to themselves")
+	}
+	return reflector, nil
+}
+
+// DefaultReflector returns the reflector held in rotors.yaml, which has contacts 1 and 16 wired to themselves.
+func DefaultReflector() enigma.Rotor {
+	reflector, err := NewReflector(rotorRegistry().MustRotor("Reflector").Wires)
+	if err != nil {
+		panic(fmt.Sprintf("reflector in rotors.yaml: %s", err))
+	}
+	return reflector
+}
@@ -0,0 +1,126 @@
+// Package hebern simulates the Hebern Electric Code machines, the first rotor machines,
+// in their one rotor and five rotor forms.
+//
+// Unlike the Enigma, the Hebern machines have no reflector. The signal passes through the rotors once,
+// so deciphering needs the reverse path through the rotors and a machine set to decipher.
+// This also allows a letter to be enciphered to itself, which the Enigma's reflector prevents.
+package hebern
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"errors"
+	"fmt"
+)
+
+// A Hebern is the representation of the rotors of the machine from left to right.
+// The rotors step as an odometer after every letter: the right rotor always steps,
+// and each other rotor steps when the rotor on its right steps from a notch in its TurnoverList.
+type Hebern struct {
+	Rotors []enigma.Rotor
+}
+
+// NewSingleRotor creates the one rotor Hebern Electric Code machine.
+func NewSingleRotor(rotor enigma.Rotor) Hebern {
+	return Hebern{Rotors: []enigma.Rotor{rotor}}
+}
+
+// NewFiveRotor creates the five rotor Hebern machine with the rotors given from left to right.
+func NewFiveRotor(rotors [5]enigma.Rotor) Hebern {
+	return Hebern{Rotors: rotors[:]}
+}
+
+// step advances the rotors as an odometer after a letter.
+func (machine *Hebern) step() {
+	for idx := len(machine.Rotors) - 1; idx >= 0; idx-- {
+		carry := machine.Rotors[idx].AtNotch()
+		machine.Rotors[idx].Rotate()
+		if !carry {
+			return
+		}
+	}
+}
+
+// alphabet returns the Alphabet the rotors are lettered with, which every rotor must share.
+//
+// # Errors
+//
+// An error is returned if there are no rotors, or a rotor is lettered with a different Alphabet
+// or does not have a wire for each symbol.
+func (machine *Hebern) alphabet() (enigma.Alphabet, error) {
+	if len(machine.Rotors) == 0 {
+		return enigma.Alphabet{}, errors.New("hebern must have at least one rotor")
+	}
+	alphabet := machine.Rotors[0].Alphabet
+	for _, rotor := range machine.Rotors {
+		if len(rotor.Wires) != alphabet.Size() {
+			return alphabet, fmt.Errorf("rotor %s must have %d wires to match the alphabet", rotor.Name, alphabet.Size())
+		}
+		if rotor.Alphabet.Symbols() != alphabet.Symbols() {
+			return alphabet, fmt.Errorf("rotor %s is lettered %s, not %s", rotor.Name, rotor.Alphabet.Symbols(), alphabet.Symbols())
+		}
+	}
+	return alphabet, nil
+}
+
+// indices checks the rotors and text, returning the Alphabet of the rotors and the index of each symbol of the text in it.
+func (machine *Hebern) indices(text string) (enigma.Alphabet, []byte, error) {
+	alphabet, err := machine.alphabet()
+	if err != nil {
+		return alphabet, []byte{}, err
+	}
+	if !alphabet.Valid(text) {
+		if alphabet.Symbols() == enigma.LatinAlphabet().Symbols() {
+			return alphabet, []byte{}, errors.New("hebern input must be capitalized ascii letters only")
+		}
+		return alphabet, []byte{}, fmt.Errorf("hebern input must only contain the symbols %s", alphabet.Symbols())
+	}
+	indices, err := alphabet.Indices(text)
+	return alphabet, indices, err
+}
+
+// Encrypt enciphers a plaintext string, passing each letter through the rotors from right to left.
+// Letters are taken from the Alphabet the rotors are lettered with.
+//
+// # Errors
+//
+// If the encryption cannot complete due to characters not in the rotors' Alphabet,
+// or rotors lettered with different Alphabets, then a non-fatal error is returned.
+func (machine *Hebern) Encrypt(plaintext string) (string, error) {
+	alphabet, indices, err := machine.indices(plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	var cipher []byte
+	for _, chr := range indices {
+		for rotorIndex := len(machine.Rotors) - 1; rotorIndex >= 0; rotorIndex-- {
+			chr = machine.Rotors[rotorIndex].Translate(chr)
+		}
+		cipher = append(cipher, alphabet.Symbol(chr))
+		machine.step()
+	}
+	return string(cipher), nil
+}
+
+// Decrypt deciphers a ciphertext string, passing each letter back through the rotors from left to right.
+//
+// # Errors
+//
+// If the decryption cannot complete due to characters not in the rotors' Alphabet,
+// or rotors lettered with different Alphabets, then a non-fatal error is returned.
+func (machine *Hebern) Decrypt(ciphertext string) (string, error) {
+	alphabet, indices, err := machine.indices(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	var plain []byte
+	for _, chr := range indices {
+		for _, rotor := range machine.Rotors {
+			chr = rotor.TranslateReverse(chr)
+		}
+		plain = append(plain, alphabet.Symbol(chr))
+		machine.step()
+	}
+	return string(plain), nil
+}
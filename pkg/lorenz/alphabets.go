@@ -0,0 +1,194 @@
+package lorenz
+
+import (
+	"errors"
+	"sort"
+)
+
+// newShiftedAlphabet creates an ITA2 from letter and figure characters indexed by code,
+// using '^' and '*' as the figure and letter shift stand-ins.
+func newShiftedAlphabet(name string, letter []byte, figure []byte) ITA2 {
+	return ITA2{
+		Name:           name,
+		letterAlphabet: bimapFromSlice(letter),
+		figureAlphabet: bimapFromSlice(figure),
+		figShift:       '^',
+		letShift:       '*',
+		hasShift:       true,
+	}
+}
+
+// reverseBits returns the 5 bit code with the order of its bits reversed.
+func reverseBits(code byte) byte {
+	reversed := byte(0)
+	for i := 0; i < 5; i++ {
+		reversed = reversed<<1 | (code>>byte(i))&1
+	}
+	return reversed
+}
+
+// reverseOrder takes characters indexed by code and returns them indexed by the bit-reversed code.
+func reverseOrder(chars []byte) []byte {
+	reordered := make([]byte, len(chars))
+	for code, char := range chars {
+		reordered[reverseBits(byte(code))] = char
+	}
+	return reordered
+}
+
+// NewITA2MSB creates an ITA2 struct with the same characters as NewITA2LSB but with the bit order of every code reversed,
+// so that the impulse carried by the least significant bit in NewITA2LSB is carried by the most significant bit.
+func NewITA2MSB() ITA2 {
+	return newShiftedAlphabet("ita2-msb", reverseOrder(ita2Letters()), reverseOrder(ita2Figures()))
+}
+
+// NewUSTTY creates an ITA2 struct using the US Teletype figure set.
+// The letters are the same as ITA2 while the figures differ for D, F, G, H, J, S, V and Z.
+// The bell, found on S in figure shift, is represented by '@'.
+func NewUSTTY() ITA2 {
+	figure := ita2Figures()
+	letter := ita2Letters()
+	usFigures := map[byte]byte{
+		'D': '$',
+		'F': '!',
+		'G': '&',
+		'H': '#',
+		'J': '\'',
+		'S': '@', // Bell
+		'V': ';',
+		'Z': '"',
+		' ': ' ',
+	}
+	for code, char := range letter {
+		if fig, exists := usFigures[char]; exists {
+			figure[code] = fig
+		}
+	}
+	return newShiftedAlphabet("ustty", letter, figure)
+}
+
+// NewBaudot creates an ITA2 struct using the International Telegraph Alphabet No. 1,
+// the standardised form of Baudot's original code which ITA2 later replaced.
+//
+// Figure shift gives the digits on the keys that carried them (A, E, Y, U, O, J, G, B, C, D).
+// Every other key returns its letter in figure shift.
+// '±' is the blank, '#' the erasure, '^' figure shift and '*' letter shift.
+func NewBaudot() ITA2 {
+	// Codes are written with impulse I as the most significant bit.
+	codes := map[byte]byte{
+		'±': 0b00000, // Blank
+		'*': 0b00001, // Letter shift
+		'^': 0b00010, // Figure shift
+		'#': 0b00011, // Erasure
+		'Y': 0b00100,
+		'S': 0b00101,
+		'B': 0b00110,
+		'R': 0b00111,
+		'E': 0b01000,
+		'X': 0b01001,
+		'G': 0b01010,
+		'M': 0b01011,
+		'I': 0b01100,
+		'W': 0b01101,
+		'F': 0b01110,
+		'N': 0b01111,
+		'A': 0b10000,
+		'_': 0b10001, // Unused in letter shift, standing in for the line break
+		'J': 0b10010,
+		'K': 0b10011,
+		'U': 0b10100,
+		'T': 0b10101,
+		'C': 0b10110,
+		'Q': 0b10111,
+		'É': 0b11000,
+		'Z': 0b11001,
+		'H': 0b11010,
+		'L': 0b11011,
+		'O': 0b11100,
+		'V': 0b11101,
+		'D': 0b11110,
+		'P': 0b11111,
+	}
+	digits := map[byte]byte{
+		'A': '1',
+		'E': '2',
+		'Y': '3',
+		'U': '4',
+		'O': '5',
+		'J': '6',
+		'G': '7',
+		'B': '8',
+		'C': '9',
+		'D': '0',
+	}
+
+	letter := make([]byte, 32)
+	figure := make([]byte, 32)
+	for char, code := range codes {
+		letter[code] = char
+		figure[code] = char
+		if digit, exists := digits[char]; exists {
+			figure[code] = digit
+		}
+	}
+	return newShiftedAlphabet("baudot", letter, figure)
+}
+
+// NewBletchleyNotation creates an ITA2 struct using the teleprinter notation of Bletchley Park.
+// Every code is written as the letter it prints in letter shift,
+// with the remaining codes written as '/' for NULL, '9' for space, '3' for carriage return, '4' for line feed,
+// '5' for figure shift and '8' for letter shift.
+// As every code has its own symbol there are no shifts, making it suitable for writing out ciphertext and key.
+func NewBletchleyNotation() ITA2 {
+	notation := map[byte]byte{
+		'±': '/',
+		' ': '9',
+		'_': '3',
+		'|': '4',
+		'^': '5',
+		'*': '8',
+	}
+	symbols := ita2Letters()
+	for code, char := range symbols {
+		if symbol, exists := notation[char]; exists {
+			symbols[code] = symbol
+		}
+	}
+	return ITA2{
+		Name:           "bletchley",
+		letterAlphabet: bimapFromSlice(symbols),
+		figureAlphabet: bimapFromSlice(symbols),
+	}
+}
+
+// alphabets holds the constructor for each alphabet that can be selected by name.
+var alphabets = map[string]func() ITA2{
+	"ita2":      NewITA2LSB,
+	"ita2-msb":  NewITA2MSB,
+	"ustty":     NewUSTTY,
+	"baudot":    NewBaudot,
+	"bletchley": NewBletchleyNotation,
+}
+
+// AlphabetNames returns the names of every alphabet that can be passed to NewAlphabet, in alphabetical order.
+func AlphabetNames() []string {
+	names := make([]string, 0, len(alphabets))
+	for name := range alphabets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAlphabet returns the alphabet with the given name.
+//
+// # Errors
+//
+// An error is returned if there is no alphabet with that name.
+func NewAlphabet(name string) (ITA2, error) {
+	constructor, exists := alphabets[name]
+	if !exists {
+		return ITA2{}, errors.New("unknown alphabet")
+	}
+	return constructor(), nil
+}
@@ -0,0 +1,268 @@
+package lorenz
+
+import (
+	"errors"
+	"strings"
+)
+
+// shiftCodes returns the codes of the figure shift and letter shift in the alphabet.
+func (alphabet *ITA2) shiftCodes() (fig byte, let byte) {
+	fig, _ = alphabet.letterAlphabet.GetITA2Code(alphabet.figShift)
+	let, _ = alphabet.letterAlphabet.GetITA2Code(alphabet.letShift)
+	return fig, let
+}
+
+// nextShift returns the shift state a teleprinter is left in after receiving a code.
+func (alphabet *ITA2) nextShift(code byte, inLetterShift bool, unshiftOnSpace bool) bool {
+	if !alphabet.hasShift {
+		return true
+	}
+	fig, let := alphabet.shiftCodes()
+	switch code {
+	case fig:
+		return false
+	case let:
+		return true
+	}
+	if space, exists := alphabet.letterAlphabet.GetITA2Code(' '); unshiftOnSpace && exists && code == space {
+		return true
+	}
+	return inLetterShift
+}
+
+// An Encoder converts ASCII text into ITA2 codes, keeping track of the shift state between calls to Encode.
+//
+// A shift code is inserted whenever a character is only found in the other shift.
+// Characters found in both shifts, such as the shift stand-ins themselves, are sent without changing shift.
+//
+// UnshiftOnSpace returns the Encoder to letter shift after every space, as US Teletype machines did.
+// ExplicitShifts stops any shift codes from being inserted, leaving the text to carry its own shift stand-ins.
+type Encoder struct {
+	alphabet       ITA2
+	inLetterShift  bool
+	UnshiftOnSpace bool
+	ExplicitShifts bool
+}
+
+// NewEncoder is a constructor, returning an Encoder for the alphabet starting in letter shift.
+func NewEncoder(alphabet ITA2) Encoder {
+	return Encoder{
+		alphabet:      alphabet,
+		inLetterShift: true,
+	}
+}
+
+// Reset returns the Encoder to letter shift.
+func (e *Encoder) Reset() {
+	e.inLetterShift = true
+}
+
+// InLetterShift returns whether the Encoder is currently in letter shift.
+func (e *Encoder) InLetterShift() bool {
+	return e.inLetterShift
+}
+
+// Encode takes a string and returns the raw 5 bit ITA2 codes for it, including any shift codes needed.
+//
+// # Errors
+//
+// An error will be returned if one of the characters in the string does not appear in the alphabet.
+func (e *Encoder) Encode(s string) ([]byte, error) {
+	encoded := []byte{}
+	fig, let := e.alphabet.shiftCodes()
+	for _, char := range s {
+		if char > 0xff {
+			return []byte{}, errors.New("invalid characters in input string")
+		}
+
+		current, other := e.alphabet.letterAlphabet, e.alphabet.figureAlphabet
+		if !e.inLetterShift {
+			current, other = other, current
+		}
+
+		code, exists := current.GetITA2Code(byte(char))
+		if !exists {
+			code, exists = other.GetITA2Code(byte(char))
+			if !exists {
+				return []byte{}, errors.New("invalid characters in input string")
+			}
+			if e.alphabet.hasShift && !e.ExplicitShifts {
+				shift := fig
+				if !e.inLetterShift {
+					shift = let
+				}
+				encoded = append(encoded, shift)
+				e.inLetterShift = !e.inLetterShift
+			}
+		}
+
+		encoded = append(encoded, code)
+		e.inLetterShift = e.alphabet.nextShift(code, e.inLetterShift, e.UnshiftOnSpace)
+	}
+	return encoded, nil
+}
+
+// A Decoder converts ITA2 codes into ASCII text, keeping track of the shift state between calls to Decode.
+//
+// Shift codes change the state of the Decoder and are left out of the text unless ShowShifts is set,
+// in which case they are written as their stand-ins.
+// UnshiftOnSpace returns the Decoder to letter shift after every space, and should match the Encoder used.
+type Decoder struct {
+	alphabet       ITA2
+	inLetterShift  bool
+	UnshiftOnSpace bool
+	ShowShifts     bool
+}
+
+// NewDecoder is a constructor, returning a Decoder for the alphabet starting in letter shift.
+func NewDecoder(alphabet ITA2) Decoder {
+	return Decoder{
+		alphabet:      alphabet,
+		inLetterShift: true,
+	}
+}
+
+// Reset returns the Decoder to letter shift.
+func (d *Decoder) Reset() {
+	d.inLetterShift = true
+}
+
+// InLetterShift returns whether the Decoder is currently in letter shift.
+func (d *Decoder) InLetterShift() bool {
+	return d.inLetterShift
+}
+
+// Decode takes a slice of ITA2 codes and returns the text they print.
+//
+// # Errors
+//
+// An error will be returned if there is no corresponding ASCII character for a code.
+func (d *Decoder) Decode(b []byte) (string, error) {
+	var decoded strings.Builder
+	fig, let := d.alphabet.shiftCodes()
+	for _, code := range b {
+		isShift := d.alphabet.hasShift && (code == fig || code == let)
+		if !isShift || d.ShowShifts {
+			table := d.alphabet.letterAlphabet
+			if !d.inLetterShift {
+				table = d.alphabet.figureAlphabet
+			}
+			plain, exists := table.GetASCII(code)
+			if !exists {
+				return "", errors.New("incorrect character sequence")
+			}
+			decoded.WriteRune(rune(plain))
+		}
+		d.inLetterShift = d.alphabet.nextShift(code, d.inLetterShift, d.UnshiftOnSpace)
+	}
+	return decoded.String(), nil
+}
+
+// FormatBinary returns the ITA2 codes written as space separated groups of 5 bits, most significant bit first.
+func FormatBinary(codes []byte) string {
+	groups := make([]string, len(codes))
+	for idx, code := range codes {
+		var group [5]byte
+		for bit := 0; bit < 5; bit++ {
+			group[bit] = '0' + (code>>byte(4-bit))&1
+		}
+		groups[idx] = string(group[:])
+	}
+	return strings.Join(groups, " ")
+}
+
+// ParseBinary reads ITA2 codes written as whitespace separated groups of 5 bits, as produced by FormatBinary.
+//
+// # Errors
+//
+// An error will be returned if a group is not made up of exactly 5 of the characters '0' and '1'.
+func ParseBinary(s string) ([]byte, error) {
+	codes := []byte{}
+	for _, group := range strings.Fields(s) {
+		if len(group) != 5 {
+			return []byte{}, errors.New("binary codes must be 5 bits long")
+		}
+		code := byte(0)
+		for _, bit := range group {
+			if bit != '0' && bit != '1' {
+				return []byte{}, errors.New("binary codes may only contain 0 and 1")
+			}
+			code = code<<1 | byte(bit-'0')
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// FormatNotation returns the ITA2 codes written in Bletchley Park notation, one symbol per code.
+func FormatNotation(codes []byte) string {
+	notation := NewBletchleyNotation()
+	decoded, _ := notation.ITA2ToAscii(codes, false)
+	return decoded
+}
+
+// ParseNotation reads ITA2 codes written in Bletchley Park notation.
+// Whitespace is ignored so that the notation may be split into groups.
+//
+// # Errors
+//
+// An error will be returned if a symbol is not part of the notation.
+func ParseNotation(s string) ([]byte, error) {
+	notation := NewBletchleyNotation()
+	return notation.AsciiToITA2(strings.Join(strings.Fields(s), ""), false)
+}
+
+// FormatNames returns the message formats accepted by EncodeFormat and DecodeFormat.
+// Text is written in the alphabet, while binary and notation give each ITA2 code directly.
+func FormatNames() []string {
+	return []string{"text", "binary", "notation"}
+}
+
+// EncodeFormat reads a message written in one of FormatNames as ITA2 codes.
+// Text is capitalised and encoded with the Encoder, while binary and notation are read directly as codes
+// so that ciphertext survives unchanged.
+//
+// # Errors
+//
+// An error will be returned if the format is unknown or the message is not valid for the format.
+func EncodeFormat(format string, message string, encoder *Encoder) ([]byte, error) {
+	switch format {
+	case "text":
+		return encoder.Encode(strings.ToUpper(message))
+	case "binary":
+		return ParseBinary(message)
+	case "notation":
+		return ParseNotation(strings.ToUpper(message))
+	default:
+		return []byte{}, errors.New("input format must be text, binary or notation")
+	}
+}
+
+// DecodeFormat writes ITA2 codes out in one of FormatNames, decoding text with the Decoder.
+//
+// # Errors
+//
+// An error will be returned if the format is unknown or the codes cannot be printed in the Decoder's alphabet.
+func DecodeFormat(format string, codes []byte, decoder *Decoder) (string, error) {
+	switch format {
+	case "text":
+		return decoder.Decode(codes)
+	case "binary":
+		return FormatBinary(codes), nil
+	case "notation":
+		return FormatNotation(codes), nil
+	default:
+		return "", errors.New("output format must be text, binary or notation")
+	}
+}
+
+// OutputFormat returns the format to write the machine's output in when none is chosen.
+// A message read as text is taken to be plaintext, so its ciphertext is written in notation,
+// which keeps every code without needing shift stand-ins.
+// A message read as binary or notation is taken to be ciphertext, so its plaintext is written as text.
+func OutputFormat(inputFormat string) string {
+	if inputFormat == "text" {
+		return "notation"
+	}
+	return "text"
+}
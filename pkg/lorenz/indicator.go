@@ -0,0 +1,353 @@
+package lorenz
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indicatorLetters is the 25 letter alphabet used to mark wheel positions, J being left out.
+const indicatorLetters = "ABCDEFGHIKLMNOPQRSTUVWXYZ"
+
+// qepBookSize is the number of lines in a QEP codebook, numbered from 00 to 99.
+const qepBookSize = 100
+
+// A Start holds the start position of every wheel of the machine.
+type Start struct {
+	Chi   [5]byte
+	Motor [2]byte
+	Psi   [5]byte
+}
+
+// SetStart sets the positions of all the machine's wheels.
+func (m *Lorenz) SetStart(s Start) {
+	m.SetChiPos(s.Chi)
+	m.SetMotorPos(s.Motor)
+	m.SetPsiPos(s.Psi)
+}
+
+// GetStart returns the current positions of all the machine's wheels.
+func (m *Lorenz) GetStart() Start {
+	var s Start
+	for i := range m.chiWheels {
+		s.Chi[i] = m.chiWheels[i].pos
+	}
+	for i := range m.motorWheels {
+		s.Motor[i] = m.motorWheels[i].pos
+	}
+	for i := range m.psiWheels {
+		s.Psi[i] = m.psiWheels[i].pos
+	}
+	return s
+}
+
+// indicatorOrder returns the positions of a Start in the order the wheels are given in an indicator:
+// Psi 1-5, the two motor wheels, then Chi 1-5, as they sat on the machine from left to right.
+func (s *Start) indicatorOrder() [12]*byte {
+	return [12]*byte{
+		&s.Psi[0], &s.Psi[1], &s.Psi[2], &s.Psi[3], &s.Psi[4],
+		&s.Motor[0], &s.Motor[1],
+		&s.Chi[0], &s.Chi[1], &s.Chi[2], &s.Chi[3], &s.Chi[4],
+	}
+}
+
+// indicatorWheelLengths returns the number of pins on each wheel in indicator order.
+func indicatorWheelLengths() [12]int {
+	wheels := NewWheelSet()
+	var lengths [12]int
+	for i := 0; i < 5; i++ {
+		lengths[i] = len(wheels.Psi[i].pins)
+		lengths[i+7] = len(wheels.Chi[i].pins)
+	}
+	lengths[5] = len(wheels.Motor[0].pins)
+	lengths[6] = len(wheels.Motor[1].pins)
+	return lengths
+}
+
+// indicatorWheelNames returns the names of the wheels in indicator order, as used in letter list files.
+func indicatorWheelNames() [12]string {
+	return [12]string{"psi1", "psi2", "psi3", "psi4", "psi5", "motor1", "motor2", "chi1", "chi2", "chi3", "chi4", "chi5"}
+}
+
+// LetterLists hold the letter list of every wheel in indicator order, mapping each letter to the position it marks.
+//
+// Each wheel carried a list of letters marking the positions that could be chosen as a start by a 12 letter indicator.
+// The lists were part of the key and changed along with the wheel patterns, so they must be given,
+// as written by Format, or generated with GenerateLetterLists.
+type LetterLists [12]map[byte]byte
+
+// GenerateLetterLists returns LetterLists with the letters placed at random positions using crypto/rand.
+// Each wheel is given all 25 letters, or one letter for every position on wheels with fewer than 25.
+//
+// # Errors
+//
+// An error is returned if the random number generator fails.
+func GenerateLetterLists() (LetterLists, error) {
+	var lists LetterLists
+	for idx, length := range indicatorWheelLengths() {
+		positions := make([]byte, length)
+		for pos := range positions {
+			positions[pos] = byte(pos)
+		}
+		for pos := len(positions) - 1; pos > 0; pos-- {
+			num, err := rand.Int(rand.Reader, big.NewInt(int64(pos+1)))
+			if err != nil {
+				return lists, err
+			}
+			other := num.Int64()
+			positions[pos], positions[other] = positions[other], positions[pos]
+		}
+		lists[idx] = make(map[byte]byte)
+		for k := 0; k < len(indicatorLetters) && k < length; k++ {
+			lists[idx][indicatorLetters[k]] = positions[k]
+		}
+	}
+	return lists, nil
+}
+
+// Format writes out the LetterLists, one wheel per line in indicator order, as the wheel's name followed by
+// the letter marking each of its positions, with '-' for a position without a letter.
+func (l LetterLists) Format() string {
+	var text strings.Builder
+	lengths := indicatorWheelLengths()
+	for idx, name := range indicatorWheelNames() {
+		marks := []byte(strings.Repeat("-", lengths[idx]))
+		for letter, pos := range l[idx] {
+			marks[pos] = letter
+		}
+		_, _ = fmt.Fprintf(&text, "%s %s\n", name, marks)
+	}
+	return text.String()
+}
+
+// ParseLetterLists reads LetterLists written by Format. Every wheel must be given.
+// Blank lines and lines starting with '#' are ignored.
+//
+// # Errors
+//
+// An error is returned if a line is not a known wheel name followed by one mark for each of its positions,
+// a mark is not an indicator letter or '-', a letter is used twice on a wheel, or a wheel is missing.
+func ParseLetterLists(text string) (LetterLists, error) {
+	var lists LetterLists
+	lengths := indicatorWheelLengths()
+	names := indicatorWheelNames()
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		idx := -1
+		for i, name := range names {
+			if strings.EqualFold(fields[0], name) {
+				idx = i
+			}
+		}
+		if idx == -1 || len(fields) != 2 {
+			return lists, fmt.Errorf("line %q must be a wheel name followed by its letters", line)
+		}
+		if lists[idx] != nil {
+			return lists, fmt.Errorf("letter list for %s is given twice", names[idx])
+		}
+		marks := strings.ToUpper(fields[1])
+		if len(marks) != lengths[idx] {
+			return lists, fmt.Errorf("letter list for %s must have %d marks, not %d", names[idx], lengths[idx], len(marks))
+		}
+		lists[idx] = make(map[byte]byte)
+		for pos := range []byte(marks) {
+			letter := marks[pos]
+			if letter == '-' {
+				continue
+			}
+			if strings.IndexByte(indicatorLetters, letter) == -1 {
+				return lists, fmt.Errorf("mark %c in the letter list for %s is not an indicator letter", letter, names[idx])
+			}
+			if _, exists := lists[idx][letter]; exists {
+				return lists, fmt.Errorf("letter %c is given twice in the letter list for %s", letter, names[idx])
+			}
+			lists[idx][letter] = byte(pos)
+		}
+	}
+	for idx, list := range lists {
+		if list == nil {
+			return lists, fmt.Errorf("letter list for %s is missing", names[idx])
+		}
+	}
+	return lists, nil
+}
+
+// Indicator returns the 12 letter indicator for a Start, such as HQIBPEXEZMUG.
+// The letters give the positions of Psi 1-5, the two motor wheels, then Chi 1-5.
+//
+// # Errors
+//
+// An error is returned if a wheel's position has no letter in that wheel's letter list.
+func (l LetterLists) Indicator(s Start) (string, error) {
+	var indicator strings.Builder
+	for idx, pos := range s.indicatorOrder() {
+		found := false
+		for letter, letterPos := range l[idx] {
+			if letterPos == *pos {
+				indicator.WriteByte(letter)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("position %d of %s has no letter in its letter list", *pos, indicatorWheelNames()[idx])
+		}
+	}
+	return indicator.String(), nil
+}
+
+// Lookup returns the Start given by a 12 letter indicator.
+//
+// # Errors
+//
+// An error is returned if the indicator is not 12 letters or a letter is not in the letter list of its wheel.
+func (l LetterLists) Lookup(indicator string) (Start, error) {
+	var s Start
+	indicator = strings.ToUpper(strings.TrimSpace(indicator))
+	if len(indicator) != 12 {
+		return s, errors.New("indicator must be 12 letters long")
+	}
+	for idx, pos := range s.indicatorOrder() {
+		letterPos, exists := l[idx][indicator[idx]]
+		if !exists {
+			return s, fmt.Errorf("letter %c is not in the letter list of %s", indicator[idx], indicatorWheelNames()[idx])
+		}
+		*pos = letterPos
+	}
+	return s, nil
+}
+
+// A QEPBook is a codebook of wheel starts, replacing the 12 letter indicator with the letters QEP and a line number.
+// Each line of the book was only to be used once.
+type QEPBook map[int]Start
+
+// GenerateQEPBook returns a QEPBook of 100 lines with every start chosen at random using crypto/rand.
+//
+// # Errors
+//
+// An error is returned if the random number generator fails.
+func GenerateQEPBook() (QEPBook, error) {
+	book := make(QEPBook)
+	lengths := indicatorWheelLengths()
+	for line := 0; line < qepBookSize; line++ {
+		var s Start
+		for idx, pos := range s.indicatorOrder() {
+			num, err := rand.Int(rand.Reader, big.NewInt(int64(lengths[idx])))
+			if err != nil {
+				return book, err
+			}
+			*pos = byte(num.Int64())
+		}
+		book[line] = s
+	}
+	return book, nil
+}
+
+// ParseQEPBook reads a QEPBook written as lines of a QEP number followed by 12 positions, as produced by Format.
+// Blank lines are ignored.
+//
+// # Errors
+//
+// An error is returned if a line does not have 13 numbers or a position is not valid for its wheel.
+func ParseQEPBook(text string) (QEPBook, error) {
+	book := make(QEPBook)
+	lengths := indicatorWheelLengths()
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 13 {
+			return book, errors.New("QEP book lines must hold a number and 12 positions")
+		}
+		number, err := strconv.Atoi(fields[0])
+		if err != nil || number < 0 || number >= qepBookSize {
+			return book, errors.New("QEP numbers must be between 00 and 99")
+		}
+
+		var s Start
+		for idx, pos := range s.indicatorOrder() {
+			num, err := strconv.Atoi(fields[idx+1])
+			if err != nil || num < 0 || num >= lengths[idx] {
+				return book, fmt.Errorf("position for wheel %d on QEP %02d is not valid for that wheel", idx+1, number)
+			}
+			*pos = byte(num)
+		}
+		book[number] = s
+	}
+	return book, nil
+}
+
+// Format writes out the QEPBook with one line per QEP number, giving the positions in indicator order.
+func (b QEPBook) Format() string {
+	numbers := make([]int, 0, len(b))
+	for number := range b {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	var text strings.Builder
+	for _, number := range numbers {
+		s := b[number]
+		_, _ = fmt.Fprintf(&text, "%02d", number)
+		for _, pos := range s.indicatorOrder() {
+			_, _ = fmt.Fprintf(&text, " %d", *pos)
+		}
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
+
+// IsQEPIndicator returns whether an indicator is a QEP indicator, the letters QEP followed by a number such as "QEP 17",
+// rather than a 12 letter indicator. A 12 letter indicator may itself begin with QEP, so it is told apart by the number.
+func IsQEPIndicator(indicator string) bool {
+	indicator = strings.ToUpper(strings.TrimSpace(indicator))
+	if !strings.HasPrefix(indicator, "QEP") {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimSpace(indicator[len("QEP"):]))
+	return err == nil
+}
+
+// Lookup returns the Start for a QEP indicator, written as "QEP 17", "QEP17" or "17".
+//
+// # Errors
+//
+// An error is returned if the indicator is not a QEP number or the number is not in the book.
+func (b QEPBook) Lookup(indicator string) (Start, error) {
+	indicator = strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(indicator)), "QEP"))
+	number, err := strconv.Atoi(indicator)
+	if err != nil {
+		return Start{}, errors.New("QEP indicator must be QEP followed by a number")
+	}
+	s, exists := b[number]
+	if !exists {
+		return Start{}, fmt.Errorf("QEP %02d is not in the book", number)
+	}
+	return s, nil
+}
+
+// Indicator returns the QEP indicator for a Start, such as "QEP 17", choosing the lowest numbered line that holds it.
+//
+// # Errors
+//
+// An error is returned if no line of the book holds the Start.
+func (b QEPBook) Indicator(s Start) (string, error) {
+	best := -1
+	for number, start := range b {
+		if start == s && (best == -1 || number < best) {
+			best = number
+		}
+	}
+	if best == -1 {
+		return "", errors.New("start is not in the QEP book")
+	}
+	return fmt.Sprintf("QEP %02d", best), nil
+}
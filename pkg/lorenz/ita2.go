@@ -1,7 +1,5 @@
 package lorenz
 
-import "errors"
-
 type bimap struct {
 	forwardMap map[byte]byte
 	reverseMap map[byte]byte
@@ -35,16 +33,24 @@ func (m *bimap) GetASCII(k byte) (byte, bool) {
 	return v, Ok
 }
 
+// An ITA2 holds a 5 bit teleprinter alphabet, mapping codes to ASCII characters in both letter and figure shift.
+// Control codes with no printable form are represented by ASCII stand-ins:
+// '±' for NULL, '_' and '|' for carriage return and line feed, '^' for figure shift and '*' for letter shift.
+//
+// An alphabet without shifts, such as the Bletchley Park notation, gives every code a single symbol
+// and has no codes treated as shift codes.
 type ITA2 struct {
+	Name           string
 	letterAlphabet bimap
 	figureAlphabet bimap
 	figShift       byte
 	letShift       byte
+	hasShift       bool
 }
 
-// NewITA2LSB creates an ITA2 struct with the corresponding ITA2 alphabets with the least significant bit on the left.
-func NewITA2LSB() ITA2 {
-	letter := []byte{
+// ita2Letters returns the ITA2 letter shift characters indexed by code, with impulse 1 as the most significant bit.
+func ita2Letters() []byte {
+	return []byte{
 		'±', // NULL
 		'T',
 		'_', // CR
@@ -78,8 +84,11 @@ func NewITA2LSB() ITA2 {
 		'K',
 		'*', // Shift Out
 	}
+}
 
-	figure := []byte{
+// ita2Figures returns the ITA2 figure shift characters indexed by code, with impulse 1 as the most significant bit.
+func ita2Figures() []byte {
+	return []byte{
 		'±', // NULL
 		'5',
 		'_', // LF
@@ -113,90 +122,37 @@ func NewITA2LSB() ITA2 {
 		'(',
 		'*', // Shift Out
 	}
+}
 
-	ITA := ITA2{
-		letterAlphabet: bimapFromSlice(letter),
-		figureAlphabet: bimapFromSlice(figure),
-		figShift:       '^',
-		letShift:       '*',
-	}
-
-	return ITA
+// NewITA2LSB creates an ITA2 struct with the corresponding ITA2 alphabets with the least significant bit on the left.
+func NewITA2LSB() ITA2 {
+	return newShiftedAlphabet("ita2", ita2Letters(), ita2Figures())
 }
 
-// AsciiToITA2 takes a string s and returns a slice of the translated ITA2 bytes along with an error
+// AsciiToITA2 takes a string s and returns a slice of the translated ITA2 bytes along with an error.
+// When decrypt is true no shift codes are inserted, so the string must contain its own shift stand-ins.
+//
+// This is equivalent to an Encoder with ExplicitShifts set to decrypt, and is kept for existing callers.
 //
 // # Errors
 //
 // An error will be returned if one of the characters in the string does not appear in the ITA2 alphabet.
 func (alphabet *ITA2) AsciiToITA2(s string, decrypt bool) ([]byte, error) {
-	encoded := []byte{}
-	inLetterShift := true
-	for _, char := range s {
-		letter, letterExist := alphabet.letterAlphabet.GetITA2Code(byte(char))
-		if !letterExist {
-			figure, figureExists := alphabet.figureAlphabet.GetITA2Code(byte(char))
-			if !figureExists {
-				return []byte(""), errors.New("invalid characters in input string")
-			}
-			if inLetterShift && !decrypt {
-				itaFig, _ := alphabet.letterAlphabet.GetITA2Code(alphabet.figShift)
-				encoded = append(encoded, itaFig)
-				inLetterShift = !inLetterShift
-			}
-			encoded = append(encoded, figure)
-		} else {
-			if !inLetterShift && !decrypt {
-				itaLet, _ := alphabet.letterAlphabet.GetITA2Code(alphabet.letShift)
-				encoded = append(encoded, itaLet)
-				inLetterShift = !inLetterShift
-			}
-			encoded = append(encoded, letter)
-		}
-	}
-	return encoded, nil
+	encoder := NewEncoder(*alphabet)
+	encoder.ExplicitShifts = decrypt
+	return encoder.Encode(s)
 }
 
 // ITA2ToAscii takes a slice of ITA2 bytes and returns a string of ASCII characters along with an error.
+// When decrypt is false the shift codes are kept in the string as their stand-ins.
+//
+// This is equivalent to a Decoder with ShowShifts set to the opposite of decrypt, and is kept for existing callers.
 //
 // # Errors
 //
 // An error will be returned if there is no corresponding ASCII character for the ITA2 byte
 func (alphabet *ITA2) ITA2ToAscii(b []byte, decrypt bool) (string, error) {
-	decoded := ""
-	inLetterShift := true
-	for _, char := range b {
-		figShiftByte, _ := alphabet.letterAlphabet.GetITA2Code(alphabet.figShift)
-		letShiftByte, _ := alphabet.letterAlphabet.GetITA2Code(alphabet.letShift)
-		if char == figShiftByte {
-			inLetterShift = false
-			if decrypt {
-				continue
-			}
-
-		} else if char == letShiftByte {
-			inLetterShift = true
-			if decrypt {
-				continue
-			}
-		}
-
-		var plain byte
-		var plainExist bool
-
-		if inLetterShift {
-			plain, plainExist = alphabet.letterAlphabet.GetASCII(char)
-		} else {
-			plain, plainExist = alphabet.figureAlphabet.GetASCII(char)
-		}
-
-		if !plainExist {
-			return "", errors.New("incorrect character sequence")
-		}
-		decoded += string(plain)
-
-	}
-
-	return decoded, nil
-
+	decoder := NewDecoder(*alphabet)
+	decoder.ShowShifts = !decrypt
+	return decoder.Decode(b)
 }
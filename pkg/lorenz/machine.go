@@ -2,6 +2,7 @@ package lorenz
 
 import (
 	"EnigmaLorenz/pkg/util"
+	"errors"
 	"log"
 )
 
@@ -94,35 +95,144 @@ func (m *Lorenz) SetMotorPos(positions [2]byte) {
 
 // Encrypt takes a slice of bytes and returns the result of them passing through the Lorenz machine.
 func (m *Lorenz) Encrypt(plain []byte) []byte {
-	ciphertext := []byte{}
+	ciphertext := make([]byte, 0, len(plain))
 
 	for _, char := range plain {
-		key := byte(0)
-		// Apply wheels
-		key = WheelsToByte(m.chiWheels[:])
-		psi := WheelsToByte(m.psiWheels[:])
-		key = key ^ psi
-		ciphertext = append(ciphertext, char^key)
-		// Rotate chi wheels
-		for i := 0; i < len(m.chiWheels); i++ {
-			m.chiWheels[i].rotate()
-		}
+		state := m.currentKey()
+		ciphertext = append(ciphertext, char^state.chi^state.psi)
+		m.step()
+	}
+	return ciphertext
+}
 
-		// Rotate psi wheels
-		if m.motorWheels[1].getCurrentPin() {
-			for i := 0; i < len(m.psiWheels); i++ {
-				m.psiWheels[i].rotate()
-			}
-		}
+// A keyState holds what each group of wheels contributes to a single character of key.
+type keyState struct {
+	chi   byte
+	psi   byte
+	motor byte
+}
+
+// currentKey returns the contribution of each group of wheels at the machine's current position.
+// The motor contribution has the first motor wheel's pin in bit 1 and the second motor wheel's pin in bit 0.
+func (m *Lorenz) currentKey() keyState {
+	return keyState{
+		chi:   WheelsToByte(m.chiWheels[:]),
+		psi:   WheelsToByte(m.psiWheels[:]),
+		motor: WheelsToByte(m.motorWheels[:]),
+	}
+}
+
+// step advances the machine by one character.
+func (m *Lorenz) step() {
+	// Rotate chi wheels
+	for i := 0; i < len(m.chiWheels); i++ {
+		m.chiWheels[i].rotate()
+	}
 
-		// Rotate motor wheels
-		m.motorWheels[0].rotate()
-		if m.motorWheels[0].getCurrentPin() {
-			m.motorWheels[1].rotate()
+	// Rotate psi wheels
+	if m.motorWheels[1].getCurrentPin() {
+		for i := 0; i < len(m.psiWheels); i++ {
+			m.psiWheels[i].rotate()
 		}
+	}
 
+	// Rotate motor wheels
+	m.motorWheels[0].rotate()
+	if m.motorWheels[0].getCurrentPin() {
+		m.motorWheels[1].rotate()
 	}
-	return ciphertext
+}
+
+// keyStates returns the next n keyStates of the machine without changing the position of its wheels.
+func (m *Lorenz) keyStates(n int) []keyState {
+	clone := *m
+	states := make([]keyState, n)
+	for i := 0; i < n; i++ {
+		states[i] = clone.currentKey()
+		clone.step()
+	}
+	return states
+}
+
+// Keystream returns the next n characters of key (Χ + Ψ') as ITA2 codes.
+// The position of the machine's wheels is not changed.
+func (m *Lorenz) Keystream(n int) []byte {
+	key := make([]byte, n)
+	for i, state := range m.keyStates(n) {
+		key[i] = state.chi ^ state.psi
+	}
+	return key
+}
+
+// ChiStream returns the next n characters produced by the Chi wheels (Χ) as ITA2 codes.
+// The position of the machine's wheels is not changed.
+func (m *Lorenz) ChiStream(n int) []byte {
+	chi := make([]byte, n)
+	for i, state := range m.keyStates(n) {
+		chi[i] = state.chi
+	}
+	return chi
+}
+
+// PsiStream returns the next n characters produced by the Psi wheels as ITA2 codes.
+// As the Psi wheels do not always move, this is the extended stream (Ψ') with repeated characters where they stood still.
+// The position of the machine's wheels is not changed.
+func (m *Lorenz) PsiStream(n int) []byte {
+	psi := make([]byte, n)
+	for i, state := range m.keyStates(n) {
+		psi[i] = state.psi
+	}
+	return psi
+}
+
+// MotorStream returns the pins of the motor wheels for the next n characters.
+// Bit 1 of each byte holds the pin of the first motor wheel and bit 0 the pin of the second,
+// whose value decides whether the Psi wheels move after that character.
+// The position of the machine's wheels is not changed.
+func (m *Lorenz) MotorStream(n int) []byte {
+	motor := make([]byte, n)
+	for i, state := range m.keyStates(n) {
+		motor[i] = state.motor
+	}
+	return motor
+}
+
+// DeChi returns the result of removing the machine's Chi stream from a slice of ITA2 ciphertext codes (Z + Χ).
+// The position of the machine's wheels is not changed.
+func (m *Lorenz) DeChi(cipher []byte) []byte {
+	dechi, _ := Add(cipher, m.ChiStream(len(cipher)))
+	return dechi
+}
+
+// Add returns the character by character addition (XOR) of two slices of ITA2 codes,
+// as is done to combine plaintext with key or to remove a stream from ciphertext.
+//
+// # Errors
+//
+// An error is returned if the two slices are not the same length.
+func Add(a []byte, b []byte) ([]byte, error) {
+	if len(a) != len(b) {
+		return []byte{}, errors.New("streams must be the same length to be added")
+	}
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result, nil
+}
+
+// Delta returns the delta of a slice of ITA2 codes, where each character is added to the one after it.
+// Applied to ciphertext this gives ΔZ and applied to key it gives ΔK.
+// The result is one character shorter than the input.
+func Delta(codes []byte) []byte {
+	if len(codes) < 2 {
+		return []byte{}
+	}
+	result := make([]byte, len(codes)-1)
+	for i := 0; i < len(codes)-1; i++ {
+		result[i] = codes[i] ^ codes[i+1]
+	}
+	return result
 }
 
 // WheelsToByte takes a list of wheels and uses the current pin to create a byte with each bit mapping to each pin.
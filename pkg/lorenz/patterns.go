@@ -0,0 +1,332 @@
+package lorenz
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxPinRun is the longest run of identical pins allowed on a Chi or Psi wheel.
+const maxPinRun = 4
+
+// maxGenerateAttempts bounds the number of random patterns tried for each wheel by GenerateWheelSet.
+const maxGenerateAttempts = 10000
+
+// A PatternViolation describes a rule for wheel patterns that a wheel breaks.
+type PatternViolation struct {
+	Wheel  string
+	Rule   string
+	Detail string
+}
+
+// Error returns the violation as a single line of text, so a PatternViolation may be used as an error.
+func (v PatternViolation) Error() string {
+	return fmt.Sprintf("%s breaks rule %q: %s", v.Wheel, v.Rule, v.Detail)
+}
+
+// countCrosses returns the number of crosses in a pattern.
+func countCrosses(pins []bool) int {
+	crosses := 0
+	for _, pin := range pins {
+		if pin {
+			crosses++
+		}
+	}
+	return crosses
+}
+
+// countDeltaCrosses returns the number of crosses in the delta of a pattern, treating it as a loop.
+func countDeltaCrosses(pins []bool) int {
+	crosses := 0
+	for i := range pins {
+		if pins[i] != pins[(i+1)%len(pins)] {
+			crosses++
+		}
+	}
+	return crosses
+}
+
+// longestRun returns the longest run of the given pin value in a pattern, treating it as a loop.
+func longestRun(pins []bool, value bool) int {
+	longest := 0
+	for start := range pins {
+		run := 0
+		for run < len(pins) && pins[(start+run)%len(pins)] == value {
+			run++
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// chiPsiViolations returns the rules broken by the pattern of a Chi or Psi wheel.
+func chiPsiViolations(name string, pins []bool, isChi bool) []PatternViolation {
+	violations := []PatternViolation{}
+	n := len(pins)
+
+	if crosses := countCrosses(pins); 2*crosses < n-2 || 2*crosses > n+2 {
+		violations = append(violations, PatternViolation{name, "half crosses",
+			fmt.Sprintf("%d of %d pins are crosses", crosses, n)})
+	}
+
+	for _, value := range []bool{true, false} {
+		if run := longestRun(pins, value); run > maxPinRun {
+			violations = append(violations, PatternViolation{name, "limited runs",
+				fmt.Sprintf("%d %s in a row, at most %d allowed", run, pinName(value), maxPinRun)})
+		}
+	}
+
+	if isChi {
+		if crosses := countDeltaCrosses(pins); 3*crosses < n || 3*crosses > 2*n {
+			violations = append(violations, PatternViolation{name, "balanced delta",
+				fmt.Sprintf("%d of %d delta pins are crosses", crosses, n)})
+		}
+	}
+	return violations
+}
+
+// motorViolations returns the rules broken by the pattern of a motor wheel.
+func motorViolations(name string, pins []bool) []PatternViolation {
+	violations := []PatternViolation{}
+	n := len(pins)
+
+	if crosses := countCrosses(pins); 5*crosses < 3*n || 5*crosses > 4*n {
+		violations = append(violations, PatternViolation{name, "mostly crosses",
+			fmt.Sprintf("%d of %d pins are crosses, 60%% to 80%% required", crosses, n)})
+	}
+	if run := longestRun(pins, false); run > 1 {
+		violations = append(violations, PatternViolation{name, "single dots",
+			fmt.Sprintf("%d dots in a row, every dot must be followed by a cross", run)})
+	}
+	if run := longestRun(pins, true); run > maxPinRun {
+		violations = append(violations, PatternViolation{name, "limited runs",
+			fmt.Sprintf("%d crosses in a row, at most %d allowed", run, maxPinRun)})
+	}
+	return violations
+}
+
+func pinName(value bool) string {
+	if value {
+		return "crosses"
+	}
+	return "dots"
+}
+
+// ValidateWheelSet checks the patterns of every wheel in a WheelSet against the rules for Tunny patterns,
+// returning every rule that is broken. An empty slice means the WheelSet follows all the rules.
+//
+// Chi and Psi wheels must have as near half crosses as possible (within one of half) and no runs of more than 4 identical pins.
+// The delta of a Chi wheel must be between one third and two thirds crosses.
+// Motor wheels must be between 60% and 80% crosses, with no run of more than 4 crosses and every dot followed by a cross.
+func ValidateWheelSet(set WheelSet) []PatternViolation {
+	violations := []PatternViolation{}
+	for i := range set.Chi {
+		violations = append(violations, chiPsiViolations(fmt.Sprintf("Chi %d", i+1), set.Chi[i].pins, true)...)
+	}
+	for i := range set.Motor {
+		violations = append(violations, motorViolations(fmt.Sprintf("Motor %d", i+1), set.Motor[i].pins)...)
+	}
+	for i := range set.Psi {
+		violations = append(violations, chiPsiViolations(fmt.Sprintf("Psi %d", i+1), set.Psi[i].pins, false)...)
+	}
+	return violations
+}
+
+// randomInt returns a random number in the range [0, n) from crypto/rand.
+func randomInt(n int) (int, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(num.Int64()), nil
+}
+
+// randomChiPsiPattern returns a random pattern of n pins with half crosses, shuffled with crypto/rand.
+func randomChiPsiPattern(n int) ([]bool, error) {
+	pins := make([]bool, n)
+	for i := 0; i < n/2; i++ {
+		pins[i] = true
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return pins, err
+		}
+		pins[i], pins[j] = pins[j], pins[i]
+	}
+	return pins, nil
+}
+
+// randomMotorPattern returns a random pattern of n pins built from single dots each followed by 1 to 4 crosses.
+// The blocks may not fill the wheel exactly, in which case ok is false.
+func randomMotorPattern(n int) (pins []bool, ok bool, err error) {
+	pins = make([]bool, 0, n)
+	for len(pins) < n {
+		crosses, err := randomInt(maxPinRun)
+		if err != nil {
+			return pins, false, err
+		}
+		pins = append(pins, false)
+		for i := 0; i <= crosses; i++ {
+			pins = append(pins, true)
+		}
+	}
+	return pins, len(pins) == n, nil
+}
+
+// GenerateWheelSet returns a WheelSet with random patterns chosen with crypto/rand that follow every rule
+// checked by ValidateWheelSet. The wheels have the same number of pins as NewWheelSet.
+//
+// # Errors
+//
+// An error is returned if the random number generator fails or no pattern following the rules can be found.
+func GenerateWheelSet() (WheelSet, error) {
+	set := NewWheelSet()
+	for i := range set.Chi {
+		name := fmt.Sprintf("Chi %d", i+1)
+		pins, err := generatePattern(len(set.Chi[i].pins), func(n int) ([]bool, bool, error) {
+			pins, err := randomChiPsiPattern(n)
+			return pins, len(chiPsiViolations(name, pins, true)) == 0, err
+		})
+		if err != nil {
+			return set, err
+		}
+		set.Chi[i] = NewWheel(pins, 0)
+	}
+	for i := range set.Motor {
+		name := fmt.Sprintf("Motor %d", i+1)
+		pins, err := generatePattern(len(set.Motor[i].pins), func(n int) ([]bool, bool, error) {
+			pins, ok, err := randomMotorPattern(n)
+			return pins, ok && len(motorViolations(name, pins)) == 0, err
+		})
+		if err != nil {
+			return set, err
+		}
+		set.Motor[i] = NewWheel(pins, 0)
+	}
+	for i := range set.Psi {
+		name := fmt.Sprintf("Psi %d", i+1)
+		pins, err := generatePattern(len(set.Psi[i].pins), func(n int) ([]bool, bool, error) {
+			pins, err := randomChiPsiPattern(n)
+			return pins, len(chiPsiViolations(name, pins, false)) == 0, err
+		})
+		if err != nil {
+			return set, err
+		}
+		set.Psi[i] = NewWheel(pins, 0)
+	}
+	return set, nil
+}
+
+// generatePattern calls generate until it returns a pattern of n pins that follows the rules.
+func generatePattern(n int, generate func(n int) ([]bool, bool, error)) ([]bool, error) {
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		pins, ok, err := generate(n)
+		if err != nil {
+			return pins, err
+		}
+		if ok {
+			return pins, nil
+		}
+	}
+	return []bool{}, errors.New("no pattern following the rules could be generated")
+}
+
+// namedWheels returns the name used for each wheel when a WheelSet is written out, along with the wheel itself.
+func (set *WheelSet) namedWheels() ([]string, []*Wheel) {
+	names := []string{}
+	wheels := []*Wheel{}
+	for i := range set.Chi {
+		names = append(names, fmt.Sprintf("chi%d", i+1))
+		wheels = append(wheels, &set.Chi[i])
+	}
+	for i := range set.Motor {
+		names = append(names, fmt.Sprintf("motor%d", i+1))
+		wheels = append(wheels, &set.Motor[i])
+	}
+	for i := range set.Psi {
+		names = append(names, fmt.Sprintf("psi%d", i+1))
+		wheels = append(wheels, &set.Psi[i])
+	}
+	return names, wheels
+}
+
+// Format writes out the patterns of the WheelSet, one wheel per line,
+// as the wheel's name followed by its pins in dots (.) and crosses (x).
+func (set WheelSet) Format() string {
+	var text strings.Builder
+	names, wheels := set.namedWheels()
+	for idx, wheel := range wheels {
+		text.WriteString(names[idx])
+		text.WriteByte(' ')
+		for _, pin := range wheel.pins {
+			if pin {
+				text.WriteByte('x')
+			} else {
+				text.WriteByte('.')
+			}
+		}
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
+
+// ParseWheelSet reads a WheelSet written by Format. Every wheel must be given, each at position 0.
+// Blank lines and lines starting with '#' are ignored.
+//
+// # Errors
+//
+// An error is returned if a line is not a known wheel name followed by a pattern, a wheel is missing,
+// or a pattern does not have the same number of pins as that wheel in NewWheelSet.
+func ParseWheelSet(text string) (WheelSet, error) {
+	set := NewWheelSet()
+	names, wheels := set.namedWheels()
+	found := make(map[string]bool)
+
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return set, errors.New("wheel lines must hold a name and a pattern")
+		}
+
+		idx := -1
+		for i, name := range names {
+			if name == fields[0] {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			return set, fmt.Errorf("unknown wheel %s", fields[0])
+		}
+		if len(fields[1]) != len(wheels[idx].pins) {
+			return set, fmt.Errorf("wheel %s must have %d pins", fields[0], len(wheels[idx].pins))
+		}
+
+		pins := make([]bool, len(fields[1]))
+		for i, pin := range fields[1] {
+			switch pin {
+			case 'x', 'X':
+				pins[i] = true
+			case '.':
+			default:
+				return set, fmt.Errorf("wheel %s may only contain dots (.) and crosses (x)", fields[0])
+			}
+		}
+		*wheels[idx] = NewWheel(pins, 0)
+		found[fields[0]] = true
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return set, fmt.Errorf("wheel %s is missing", name)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,79 @@
+package lorenz
+
+import (
+	"EnigmaLorenz/pkg/util"
+	"log"
+)
+
+// advance moves the wheel on by the given number of steps, in the direction it turns.
+func (w *Wheel) advance(steps int) {
+	w.pos = byte(util.NegMod(int(w.pos)-steps, len(w.pins)))
+}
+
+// motorSteps runs the motor wheels on by n steps and returns the number of those steps after which the Psi wheels moved.
+func (m *Lorenz) motorSteps(n int) int {
+	psiSteps := 0
+	for i := 0; i < n; i++ {
+		if m.motorWheels[1].getCurrentPin() {
+			psiSteps++
+		}
+		m.motorWheels[0].rotate()
+		if m.motorWheels[0].getCurrentPin() {
+			m.motorWheels[1].rotate()
+		}
+	}
+	return psiSteps
+}
+
+// Seek moves the wheels on to where they would be after n more characters, without enciphering anything.
+// This allows decryption to start from the middle of a message, or to resynchronise after a garble.
+//
+// The Chi wheels and first motor wheel move every character so they are moved on by n directly.
+// The motor wheels return to the same positions after every full cycle of both wheels,
+// so only the remainder of n after whole cycles is simulated to find how far the Psi wheels move.
+//
+// # Errors
+//
+// A fatal error will occur if n is negative.
+func (m *Lorenz) Seek(n int) {
+	if n < 0 {
+		log.Fatal("Lorenz can only seek forwards, use StepBack to move backwards")
+	}
+
+	for i := range m.chiWheels {
+		m.chiWheels[i].advance(n)
+	}
+
+	cycle := len(m.motorWheels[0].pins) * len(m.motorWheels[1].pins)
+	psiSteps := 0
+	if n >= cycle {
+		// A full cycle leaves the motor wheels where they started, so it only needs simulating once.
+		psiSteps = (n / cycle) * m.motorSteps(cycle)
+	}
+	psiSteps += m.motorSteps(n % cycle)
+
+	for i := range m.psiWheels {
+		m.psiWheels[i].advance(psiSteps)
+	}
+}
+
+// StepBack moves the wheels back to where they were before the last character, undoing it.
+//
+// The second motor wheel moved if the first motor wheel now shows a cross,
+// and the Psi wheels moved if the second motor wheel showed a cross before that.
+func (m *Lorenz) StepBack() {
+	if m.motorWheels[0].getCurrentPin() {
+		m.motorWheels[1].advance(-1)
+	}
+	m.motorWheels[0].advance(-1)
+
+	if m.motorWheels[1].getCurrentPin() {
+		for i := range m.psiWheels {
+			m.psiWheels[i].advance(-1)
+		}
+	}
+
+	for i := range m.chiWheels {
+		m.chiWheels[i].advance(-1)
+	}
+}
@@ -0,0 +1,87 @@
+package lorenz
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// A TraceStep records the state of the machine as a single character passed through it.
+// Positions are those of the wheels as the character was enciphered, before they stepped on.
+// Motor holds the pin of the first motor wheel in bit 1 and the second motor wheel in bit 0,
+// and PsiMoved is whether the Psi wheels stepped on after the character.
+type TraceStep struct {
+	Positions Start
+	Plain     byte
+	Chi       byte
+	Psi       byte
+	Key       byte
+	Cipher    byte
+	Motor     byte
+	PsiMoved  bool
+}
+
+// EncryptTrace enciphers a slice of bytes in the same way as Encrypt,
+// also returning a TraceStep for every character describing how it was enciphered.
+func (m *Lorenz) EncryptTrace(plain []byte) ([]byte, []TraceStep) {
+	ciphertext := make([]byte, 0, len(plain))
+	trace := make([]TraceStep, 0, len(plain))
+
+	for _, char := range plain {
+		state := m.currentKey()
+		key := state.chi ^ state.psi
+		step := TraceStep{
+			Positions: m.GetStart(),
+			Plain:     char,
+			Chi:       state.chi,
+			Psi:       state.psi,
+			Key:       key,
+			Cipher:    char ^ key,
+			Motor:     state.motor,
+			PsiMoved:  m.motorWheels[1].getCurrentPin(),
+		}
+		ciphertext = append(ciphertext, step.Cipher)
+		trace = append(trace, step)
+		m.step()
+	}
+	return ciphertext, trace
+}
+
+// DotCross writes the lowest bits of a code in Bletchley notation, most significant bit first,
+// with a dot (.) for 0 and a cross (x) for 1.
+func DotCross(code byte, bits int) string {
+	var notation strings.Builder
+	for bit := bits - 1; bit >= 0; bit-- {
+		if (code>>byte(bit))&1 == 1 {
+			notation.WriteByte('x')
+		} else {
+			notation.WriteByte('.')
+		}
+	}
+	return notation.String()
+}
+
+// FormatTrace returns a table of a trace, one row per character, with every character and pin in dots and crosses.
+// The columns give the positions of the Chi, motor and Psi wheels, followed by P, Χ, Ψ', K and Z,
+// the motor pins (μ), and whether the Psi wheels moved after the character.
+func FormatTrace(trace []TraceStep) string {
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 1, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(writer, "#\tχ1\tχ2\tχ3\tχ4\tχ5\tμ1\tμ2\tψ1\tψ2\tψ3\tψ4\tψ5\t P\t Χ\t Ψ'\t K\t Z\t μ\t ψ moved\t")
+	for idx, step := range trace {
+		p := step.Positions
+		moved := "."
+		if step.PsiMoved {
+			moved = "x"
+		}
+		_, _ = fmt.Fprintf(writer, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t\n",
+			idx+1,
+			p.Chi[0], p.Chi[1], p.Chi[2], p.Chi[3], p.Chi[4],
+			p.Motor[0], p.Motor[1],
+			p.Psi[0], p.Psi[1], p.Psi[2], p.Psi[3], p.Psi[4],
+			DotCross(step.Plain, 5), DotCross(step.Chi, 5), DotCross(step.Psi, 5),
+			DotCross(step.Key, 5), DotCross(step.Cipher, 5), DotCross(step.Motor, 2), moved)
+	}
+	_ = writer.Flush()
+	return table.String()
+}
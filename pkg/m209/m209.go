@@ -0,0 +1,163 @@
+// Package m209 simulates the Hagelin M-209, the pin and lug cipher machine used by the US Army in the Second World War.
+//
+// The machine has six key wheels of 26, 25, 23, 21, 19 and 17 pins, each pin set active or inactive,
+// and a drum of 27 bars each carrying two lugs that may be set against a wheel or left neutral.
+// For every letter, the number of bars with a lug against an active pin gives the key, which is applied as a Beaufort cipher,
+// so the same settings both encipher and decipher. Every wheel then steps on by one pin.
+package m209
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NumBars is the number of bars on the drum.
+const NumBars = 27
+
+// wheelLetters holds the letters marked on each wheel, one per pin.
+var wheelLetters = [6]string{
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"ABCDEFGHIJKLMNOPQRSTUVXYZ",
+	"ABCDEFGHIJKLMNOPQRSTUVX",
+	"ABCDEFGHIJKLMNOPQRSTU",
+	"ABCDEFGHIJKLMNOPQRS",
+	"ABCDEFGHIJKLMNOPQ",
+}
+
+// wheelOffsets holds, for each wheel, how far round from the letter shown in the window the pin sensed by the drum sits.
+var wheelOffsets = [6]int{15, 14, 13, 12, 11, 10}
+
+// WheelLetters returns the letters marked on a wheel (1-6), one per pin.
+func WheelLetters(wheel int) string {
+	return wheelLetters[wheel-1]
+}
+
+// A Bar is the pair of lugs on one bar of the drum, each holding the wheel (1-6) it is set against, or 0 if neutral.
+type Bar [2]byte
+
+// A wheel holds the pins of a key wheel and the position shown in its window.
+type wheel struct {
+	pins []bool
+	pos  int
+}
+
+// active returns whether the pin sensed by the drum is active.
+func (w *wheel) active(offset int) bool {
+	return w.pins[(w.pos+offset)%len(w.pins)]
+}
+
+// An M209 is the representation of the six key wheels and the lug settings of the drum.
+type M209 struct {
+	wheels [6]wheel
+	lugs   [NumBars]Bar
+}
+
+// NewM209 creates an M209 from the given Settings, with every wheel showing A.
+//
+// # Errors
+//
+// An error is returned if the Settings are not valid.
+func NewM209(settings Settings) (M209, error) {
+	m := M209{}
+	if err := settings.Validate(); err != nil {
+		return m, err
+	}
+	for i := range m.wheels {
+		m.wheels[i].pins = append([]bool{}, settings.Pins[i]...)
+	}
+	m.lugs = settings.Lugs
+	return m, nil
+}
+
+// SetPositions sets the letter shown by each wheel, given as six letters such as "AAAAAA".
+//
+// # Errors
+//
+// An error is returned if there are not six letters or a letter is not marked on its wheel.
+func (m *M209) SetPositions(letters string) error {
+	letters = strings.ToUpper(letters)
+	if len(letters) != len(m.wheels) {
+		return errors.New("m209 positions must be six letters")
+	}
+	var positions [6]int
+	for i := range m.wheels {
+		positions[i] = strings.IndexByte(wheelLetters[i], letters[i])
+		if positions[i] == -1 {
+			return fmt.Errorf("letter %c is not on wheel %d", letters[i], i+1)
+		}
+	}
+	for i := range m.wheels {
+		m.wheels[i].pos = positions[i]
+	}
+	return nil
+}
+
+// GetPositions returns the letter shown by each wheel.
+func (m *M209) GetPositions() string {
+	var letters strings.Builder
+	for i, w := range m.wheels {
+		letters.WriteByte(wheelLetters[i][w.pos])
+	}
+	return letters.String()
+}
+
+// key returns the number of bars with a lug set against an active pin.
+func (m *M209) key() int {
+	key := 0
+	for _, bar := range m.lugs {
+		for _, lug := range bar {
+			if lug != 0 && m.wheels[lug-1].active(wheelOffsets[lug-1]) {
+				key++
+				break
+			}
+		}
+	}
+	return key
+}
+
+// step moves every wheel on by one pin.
+func (m *M209) step() {
+	for i := range m.wheels {
+		m.wheels[i].pos = (m.wheels[i].pos + 1) % len(m.wheels[i].pins)
+	}
+}
+
+// encipher passes letters (A-Z) through the machine, applying the key to each as a Beaufort cipher.
+func (m *M209) encipher(letters string) string {
+	out := make([]byte, len(letters))
+	for i := range []byte(letters) {
+		out[i] = byte((m.key()+25-int(letters[i]-'A'))%26) + 'A'
+		m.step()
+	}
+	return string(out)
+}
+
+// Encrypt enciphers plaintext, typing each space as the letter Z as the machine's operators did.
+//
+// # Errors
+//
+// An error is returned if the plaintext contains anything other than capital letters and spaces.
+func (m *M209) Encrypt(plaintext string) (string, error) {
+	for _, chr := range plaintext {
+		if (chr < 'A' || chr > 'Z') && chr != ' ' {
+			return "", errors.New("m209 plaintext must be capitalized ascii letters and spaces only")
+		}
+	}
+	return m.encipher(strings.ReplaceAll(plaintext, " ", "Z")), nil
+}
+
+// Decrypt deciphers ciphertext, printing each Z as a space. Spaces in the ciphertext, such as between groups, are ignored.
+//
+// # Errors
+//
+// An error is returned if the ciphertext contains anything other than capital letters and spaces.
+func (m *M209) Decrypt(ciphertext string) (string, error) {
+	ciphertext = strings.ReplaceAll(ciphertext, " ", "")
+	for _, chr := range ciphertext {
+		if chr < 'A' || chr > 'Z' {
+			return "", errors.New("m209 ciphertext must be capitalized ascii letters only")
+		}
+	}
+	return strings.ReplaceAll(m.encipher(ciphertext), "Z", " "), nil
+}
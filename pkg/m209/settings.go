@@ -0,0 +1,141 @@
+package m209
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Settings hold the internal key of an M209: which pins are active on each wheel and where the lugs of each bar are set.
+// Pins[i] has one entry per letter of WheelLetters(i+1), true meaning the pin is active.
+type Settings struct {
+	Pins [6][]bool
+	Lugs [NumBars]Bar
+}
+
+// Validate checks that every wheel has the right number of pins and every lug is neutral or set against a wheel,
+// with no bar having both lugs against the same wheel.
+//
+// # Errors
+//
+// An error describing the first problem found is returned.
+func (s Settings) Validate() error {
+	for i := range s.Pins {
+		if len(s.Pins[i]) != len(wheelLetters[i]) {
+			return fmt.Errorf("wheel %d must have %d pins", i+1, len(wheelLetters[i]))
+		}
+	}
+	for idx, bar := range s.Lugs {
+		for _, lug := range bar {
+			if lug > 6 {
+				return fmt.Errorf("lug on bar %d must be set against a wheel (1-6) or neutral (0)", idx+1)
+			}
+		}
+		if bar[0] != 0 && bar[0] == bar[1] {
+			return fmt.Errorf("bar %d has both lugs set against wheel %d", idx+1, bar[0])
+		}
+	}
+	return nil
+}
+
+// Format writes out the Settings as a key list, with one line giving the lugs of every bar as pairs such as 3-6,
+// followed by a line per wheel listing the letters of its active pins, or '-' if none are active.
+func (s Settings) Format() string {
+	var text strings.Builder
+	text.WriteString("lugs")
+	for _, bar := range s.Lugs {
+		_, _ = fmt.Fprintf(&text, " %d-%d", bar[0], bar[1])
+	}
+	text.WriteByte('\n')
+	for i, pins := range s.Pins {
+		_, _ = fmt.Fprintf(&text, "wheel%d ", i+1)
+		active := ""
+		for idx, pin := range pins {
+			if pin {
+				active += string(wheelLetters[i][idx])
+			}
+		}
+		if active == "" {
+			active = "-"
+		}
+		text.WriteString(active)
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
+
+// ParseSettings reads Settings written by Format. Blank lines and lines starting with '#' are ignored.
+// Lugs not given are left neutral.
+//
+// # Errors
+//
+// An error is returned if a line is not recognised, a wheel is missing, a letter is not on its wheel,
+// or the resulting Settings are not valid.
+func ParseSettings(text string) (Settings, error) {
+	s := Settings{}
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "lugs" {
+			if len(fields)-1 > NumBars {
+				return s, fmt.Errorf("drum only has %d bars", NumBars)
+			}
+			for idx, pair := range fields[1:] {
+				bar, err := parseBar(pair)
+				if err != nil {
+					return s, fmt.Errorf("bar %d: %w", idx+1, err)
+				}
+				s.Lugs[idx] = bar
+			}
+			continue
+		}
+
+		wheel, err := strconv.Atoi(strings.TrimPrefix(fields[0], "wheel"))
+		if !strings.HasPrefix(fields[0], "wheel") || err != nil || wheel < 1 || wheel > 6 {
+			return s, fmt.Errorf("unknown setting %s", fields[0])
+		}
+		if len(fields) != 2 {
+			return s, fmt.Errorf("wheel%d must be followed by its active pins", wheel)
+		}
+		letters := wheelLetters[wheel-1]
+		pins := make([]bool, len(letters))
+		if fields[1] != "-" {
+			for _, letter := range strings.ToUpper(fields[1]) {
+				idx := strings.IndexRune(letters, letter)
+				if idx == -1 {
+					return s, fmt.Errorf("letter %c is not on wheel %d", letter, wheel)
+				}
+				pins[idx] = true
+			}
+		}
+		s.Pins[wheel-1] = pins
+	}
+
+	for i := range s.Pins {
+		if s.Pins[i] == nil {
+			return s, fmt.Errorf("wheel%d is missing", i+1)
+		}
+	}
+	return s, s.Validate()
+}
+
+// parseBar reads the lugs of a bar written as a pair such as 3-6.
+func parseBar(pair string) (Bar, error) {
+	bar := Bar{}
+	lugs := strings.Split(pair, "-")
+	if len(lugs) != 2 {
+		return bar, errors.New("lugs must be written as a pair such as 3-6")
+	}
+	for i, lug := range lugs {
+		num, err := strconv.Atoi(lug)
+		if err != nil || num < 0 || num > 6 {
+			return bar, errors.New("lugs must be set against a wheel (1-6) or neutral (0)")
+		}
+		bar[i] = byte(num)
+	}
+	return bar, nil
+}
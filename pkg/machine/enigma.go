@@ -0,0 +1,84 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"errors"
+)
+
+// enigmaState holds the shown position of each rotor of an Enigma.
+type enigmaState struct {
+	left, center, right, fourth byte
+}
+
+// An Enigma adapts an enigma.Enigma to the Machine interface.
+type Enigma struct {
+	machine        enigma.Enigma
+	useFourthRotor bool
+	start          enigmaState
+}
+
+// NewEnigma returns a Machine using the given Enigma, remembering its current rotor positions for Reset.
+// useFourthRotor is passed on to enigma.Enigma.Encrypt for M4 Enigma.
+func NewEnigma(machine enigma.Enigma, useFourthRotor bool) *Enigma {
+	e := &Enigma{
+		machine:        machine,
+		useFourthRotor: useFourthRotor,
+	}
+	e.start = e.State().(enigmaState)
+	return e
+}
+
+// Name returns "Enigma M4" when the fourth rotor is in use, otherwise "Enigma".
+func (e *Enigma) Name() string {
+	if e.useFourthRotor {
+		return "Enigma M4"
+	}
+	return "Enigma"
+}
+
+// Alphabet returns the symbols the Enigma accepts.
+func (e *Enigma) Alphabet() string {
+	return e.machine.Alphabet.Symbols()
+}
+
+// Encrypt enciphers plaintext with the Enigma.
+func (e *Enigma) Encrypt(plaintext string) (string, error) {
+	return e.machine.Encrypt(plaintext, e.useFourthRotor)
+}
+
+// Decrypt deciphers ciphertext with the Enigma, which as a reciprocal machine is the same as enciphering it.
+func (e *Enigma) Decrypt(ciphertext string) (string, error) {
+	return e.machine.Encrypt(ciphertext, e.useFourthRotor)
+}
+
+// Reset returns the rotors to the positions they were in when the adapter was created.
+func (e *Enigma) Reset() {
+	_ = e.Restore(e.start)
+}
+
+// State returns the current rotor positions.
+func (e *Enigma) State() State {
+	return enigmaState{
+		left:   e.machine.LeftRotor.GetShownPos(),
+		center: e.machine.CenterRotor.GetShownPos(),
+		right:  e.machine.RightRotor.GetShownPos(),
+		fourth: e.machine.FourthRotor.GetShownPos(),
+	}
+}
+
+// Restore returns the rotors to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by an Enigma adapter.
+func (e *Enigma) Restore(state State) error {
+	s, ok := state.(enigmaState)
+	if !ok {
+		return errors.New("state is not an Enigma state")
+	}
+	e.machine.LeftRotor.SetShownPos(s.left)
+	e.machine.CenterRotor.SetShownPos(s.center)
+	e.machine.RightRotor.SetShownPos(s.right)
+	e.machine.FourthRotor.SetShownPos(s.fourth)
+	return nil
+}
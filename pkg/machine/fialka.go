@@ -0,0 +1,72 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/fialka"
+	"errors"
+)
+
+// fialkaState holds the shown position of each rotor of a Fialka.
+type fialkaState [10]byte
+
+// A Fialka adapts a fialka.Fialka to the Machine interface.
+type Fialka struct {
+	machine fialka.Fialka
+	start   fialkaState
+}
+
+// NewFialka returns a Machine using the given Fialka, remembering its current rotor positions for Reset.
+func NewFialka(machine fialka.Fialka) *Fialka {
+	f := &Fialka{machine: machine}
+	f.start = f.State().(fialkaState)
+	return f
+}
+
+// Name returns "Fialka M-125".
+func (f *Fialka) Name() string {
+	return "Fialka M-125"
+}
+
+// Alphabet returns the symbols of the Fialka's keyboard mode.
+func (f *Fialka) Alphabet() string {
+	return f.machine.Keyboard.Symbols()
+}
+
+// Encrypt enciphers plaintext with the Fialka.
+func (f *Fialka) Encrypt(plaintext string) (string, error) {
+	return f.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the Fialka, which as a reciprocal machine is the same as enciphering it.
+func (f *Fialka) Decrypt(ciphertext string) (string, error) {
+	return f.machine.Encrypt(ciphertext)
+}
+
+// Reset returns the rotors to the positions they were in when the adapter was created.
+func (f *Fialka) Reset() {
+	_ = f.Restore(f.start)
+}
+
+// State returns the current rotor positions.
+func (f *Fialka) State() State {
+	var state fialkaState
+	for i := range f.machine.Rotors {
+		state[i] = f.machine.Rotors[i].GetShownPos()
+	}
+	return state
+}
+
+// Restore returns the rotors to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a Fialka adapter.
+func (f *Fialka) Restore(state State) error {
+	s, ok := state.(fialkaState)
+	if !ok {
+		return errors.New("state is not a Fialka state")
+	}
+	for i := range f.machine.Rotors {
+		f.machine.Rotors[i].SetShownPos(s[i])
+	}
+	return nil
+}
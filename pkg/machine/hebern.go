@@ -0,0 +1,73 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/hebern"
+	"errors"
+)
+
+// A Hebern adapts a hebern.Hebern to the Machine interface.
+type Hebern struct {
+	machine hebern.Hebern
+	start   []byte
+}
+
+// NewHebern returns a Machine using the given Hebern, remembering its current rotor positions for Reset.
+// The adapter takes its own copy of the rotors, so the Hebern passed in is not moved on by it.
+func NewHebern(machine hebern.Hebern) *Hebern {
+	h := &Hebern{machine: hebern.Hebern{Rotors: append(machine.Rotors[:0:0], machine.Rotors...)}}
+	h.start = h.State().([]byte)
+	return h
+}
+
+// Name returns "Hebern" with the number of rotors, such as "Hebern 5 rotor".
+func (h *Hebern) Name() string {
+	if len(h.machine.Rotors) == 1 {
+		return "Hebern 1 rotor"
+	}
+	return "Hebern 5 rotor"
+}
+
+// Alphabet returns the letters the Hebern accepts.
+func (h *Hebern) Alphabet() string {
+	return "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+}
+
+// Encrypt enciphers plaintext with the Hebern.
+func (h *Hebern) Encrypt(plaintext string) (string, error) {
+	return h.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the Hebern.
+func (h *Hebern) Decrypt(ciphertext string) (string, error) {
+	return h.machine.Decrypt(ciphertext)
+}
+
+// Reset returns the rotors to the positions they were in when the adapter was created.
+func (h *Hebern) Reset() {
+	_ = h.Restore(h.start)
+}
+
+// State returns the current rotor positions.
+func (h *Hebern) State() State {
+	positions := make([]byte, len(h.machine.Rotors))
+	for i := range h.machine.Rotors {
+		positions[i] = h.machine.Rotors[i].GetShownPos()
+	}
+	return positions
+}
+
+// Restore returns the rotors to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a Hebern adapter with the same number of rotors.
+func (h *Hebern) Restore(state State) error {
+	positions, ok := state.([]byte)
+	if !ok || len(positions) != len(h.machine.Rotors) {
+		return errors.New("state is not a Hebern state")
+	}
+	for i := range h.machine.Rotors {
+		h.machine.Rotors[i].SetShownPos(positions[i])
+	}
+	return nil
+}
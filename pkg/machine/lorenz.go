@@ -0,0 +1,78 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/lorenz"
+	"errors"
+)
+
+// A Lorenz adapts a lorenz.Lorenz to the Machine interface.
+// Plaintext is written in the chosen teleprinter alphabet, while ciphertext is written in Bletchley Park notation
+// so that every code, including shift codes, survives being passed around as text.
+type Lorenz struct {
+	machine  lorenz.Lorenz
+	alphabet lorenz.ITA2
+	start    lorenz.Start
+}
+
+// NewLorenz returns a Machine using the given Lorenz and plaintext alphabet, remembering its current wheel positions for Reset.
+func NewLorenz(machine lorenz.Lorenz, alphabet lorenz.ITA2) *Lorenz {
+	return &Lorenz{
+		machine:  machine,
+		alphabet: alphabet,
+		start:    machine.GetStart(),
+	}
+}
+
+// Name returns "Lorenz SZ42".
+func (l *Lorenz) Name() string {
+	return "Lorenz SZ42"
+}
+
+// Alphabet returns the name of the plaintext alphabet and the ciphertext notation.
+func (l *Lorenz) Alphabet() string {
+	return l.alphabet.Name + " plaintext, bletchley notation ciphertext"
+}
+
+// Encrypt encodes plaintext with the alphabet and enciphers it, returning the ciphertext in Bletchley Park notation.
+func (l *Lorenz) Encrypt(plaintext string) (string, error) {
+	encoder := lorenz.NewEncoder(l.alphabet)
+	codes, err := encoder.Encode(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return lorenz.FormatNotation(l.machine.Encrypt(codes)), nil
+}
+
+// Decrypt deciphers ciphertext written in Bletchley Park notation and decodes it with the alphabet.
+func (l *Lorenz) Decrypt(ciphertext string) (string, error) {
+	codes, err := lorenz.ParseNotation(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	decoder := lorenz.NewDecoder(l.alphabet)
+	return decoder.Decode(l.machine.Encrypt(codes))
+}
+
+// Reset returns the wheels to the positions they were in when the adapter was created.
+func (l *Lorenz) Reset() {
+	l.machine.SetStart(l.start)
+}
+
+// State returns the current wheel positions.
+func (l *Lorenz) State() State {
+	return l.machine.GetStart()
+}
+
+// Restore returns the wheels to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a Lorenz adapter.
+func (l *Lorenz) Restore(state State) error {
+	s, ok := state.(lorenz.Start)
+	if !ok {
+		return errors.New("state is not a Lorenz state")
+	}
+	l.machine.SetStart(s)
+	return nil
+}
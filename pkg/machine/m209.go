@@ -0,0 +1,63 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/m209"
+	"errors"
+)
+
+// An M209 adapts an m209.M209 to the Machine interface.
+type M209 struct {
+	machine m209.M209
+	start   string
+}
+
+// NewM209 returns a Machine using the given M209, remembering its current wheel positions for Reset.
+func NewM209(machine m209.M209) *M209 {
+	return &M209{
+		machine: machine,
+		start:   machine.GetPositions(),
+	}
+}
+
+// Name returns "Hagelin M-209".
+func (m *M209) Name() string {
+	return "Hagelin M-209"
+}
+
+// Alphabet returns the letters the M209 accepts, noting that spaces are typed as Z.
+func (m *M209) Alphabet() string {
+	return "ABCDEFGHIJKLMNOPQRSTUVWXYZ, space typed as Z"
+}
+
+// Encrypt enciphers plaintext with the M209.
+func (m *M209) Encrypt(plaintext string) (string, error) {
+	return m.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the M209.
+func (m *M209) Decrypt(ciphertext string) (string, error) {
+	return m.machine.Decrypt(ciphertext)
+}
+
+// Reset returns the wheels to the positions they were in when the adapter was created.
+func (m *M209) Reset() {
+	_ = m.machine.SetPositions(m.start)
+}
+
+// State returns the letters shown by the wheels.
+func (m *M209) State() State {
+	return m.machine.GetPositions()
+}
+
+// Restore returns the wheels to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by an M209 adapter.
+func (m *M209) Restore(state State) error {
+	positions, ok := state.(string)
+	if !ok {
+		return errors.New("state is not an M-209 state")
+	}
+	return m.machine.SetPositions(positions)
+}
@@ -0,0 +1,28 @@
+// Package machine defines a common interface for the cipher machine simulators,
+// along with adapters that let each simulator be used through it.
+package machine
+
+// A State is an opaque snapshot of a Machine's key settings that may change as it enciphers, such as rotor positions.
+// A State can only be restored to the kind of Machine that produced it.
+type State any
+
+// A Machine enciphers and deciphers text, and can have its position saved and restored.
+//
+// Encrypt and Decrypt both move the machine on, so a message is decrypted by restoring the State
+// the machine was in before it was encrypted, or by calling Reset.
+type Machine interface {
+	// Name returns a description of the machine.
+	Name() string
+	// Alphabet returns a description of the text the machine accepts and produces.
+	Alphabet() string
+	// Encrypt enciphers plaintext, returning the ciphertext.
+	Encrypt(plaintext string) (string, error)
+	// Decrypt deciphers ciphertext, returning the plaintext.
+	Decrypt(ciphertext string) (string, error)
+	// Reset returns the machine to the position it was in when the adapter was created.
+	Reset()
+	// State returns a snapshot of the machine's current position.
+	State() State
+	// Restore returns the machine to a position saved by State.
+	Restore(state State) error
+}
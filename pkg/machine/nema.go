@@ -0,0 +1,77 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/nema"
+	"errors"
+)
+
+// nemaState holds the letter (0-25) shown by each of the ten wheels of a NEMA, from left to right.
+type nemaState [10]byte
+
+// A NEMA adapts a nema.NEMA to the Machine interface.
+type NEMA struct {
+	machine nema.NEMA
+	start   nemaState
+}
+
+// NewNEMA returns a Machine using the given NEMA, remembering its current wheel positions for Reset.
+func NewNEMA(machine nema.NEMA) *NEMA {
+	n := &NEMA{machine: machine}
+	n.start = n.State().(nemaState)
+	return n
+}
+
+// Name returns "NEMA".
+func (n *NEMA) Name() string {
+	return "NEMA"
+}
+
+// Alphabet returns the letters the NEMA accepts.
+func (n *NEMA) Alphabet() string {
+	return "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+}
+
+// Encrypt enciphers plaintext with the NEMA.
+func (n *NEMA) Encrypt(plaintext string) (string, error) {
+	return n.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the NEMA, which as a reciprocal machine is the same as enciphering it.
+func (n *NEMA) Decrypt(ciphertext string) (string, error) {
+	return n.machine.Encrypt(ciphertext)
+}
+
+// Reset returns the wheels to the positions they were in when the adapter was created.
+func (n *NEMA) Reset() {
+	_ = n.Restore(n.start)
+}
+
+// State returns the current wheel positions.
+func (n *NEMA) State() State {
+	var state nemaState
+	state[0] = n.machine.UKW.GetShownPos() - 1
+	for idx := range n.machine.ContactWheels {
+		state[1+2*idx] = n.machine.ContactWheels[idx].GetShownPos() - 1
+		state[2+2*idx] = n.machine.RedWheels[idx].GetPos()
+	}
+	state[9] = n.machine.DriveWheel.GetPos()
+	return state
+}
+
+// Restore returns the wheels to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a NEMA adapter.
+func (n *NEMA) Restore(state State) error {
+	s, ok := state.(nemaState)
+	if !ok {
+		return errors.New("state is not a NEMA state")
+	}
+	n.machine.UKW.SetShownPos(s[0] + 1)
+	for idx := range n.machine.ContactWheels {
+		n.machine.ContactWheels[idx].SetShownPos(s[1+2*idx] + 1)
+		_ = n.machine.RedWheels[idx].SetPos(s[2+2*idx])
+	}
+	return n.machine.DriveWheel.SetPos(s[9])
+}
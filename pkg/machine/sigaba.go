@@ -0,0 +1,84 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/sigaba"
+	"errors"
+)
+
+// sigabaState holds the shown position of every cipher, control and index rotor of a SIGABA.
+type sigabaState [15]byte
+
+// A SIGABA adapts a sigaba.SIGABA to the Machine interface.
+type SIGABA struct {
+	machine sigaba.SIGABA
+	start   sigabaState
+}
+
+// NewSIGABA returns a Machine using the given SIGABA, remembering its current rotor positions for Reset.
+func NewSIGABA(machine sigaba.SIGABA) *SIGABA {
+	s := &SIGABA{machine: machine}
+	s.start = s.State().(sigabaState)
+	return s
+}
+
+// rotors returns every rotor of the machine, cipher rotors first, then control and index rotors.
+func (s *SIGABA) rotors() []*enigma.Rotor {
+	rotors := []*enigma.Rotor{}
+	for _, bank := range []*[5]enigma.Rotor{&s.machine.CipherRotors, &s.machine.ControlRotors, &s.machine.IndexRotors} {
+		for i := range bank {
+			rotors = append(rotors, &bank[i])
+		}
+	}
+	return rotors
+}
+
+// Name returns "SIGABA".
+func (s *SIGABA) Name() string {
+	return "SIGABA"
+}
+
+// Alphabet returns the letters the SIGABA accepts, noting that spaces are typed as Z and Z as X.
+func (s *SIGABA) Alphabet() string {
+	return "ABCDEFGHIJKLMNOPQRSTUVWXYZ, space typed as Z and Z as X"
+}
+
+// Encrypt enciphers plaintext with the SIGABA.
+func (s *SIGABA) Encrypt(plaintext string) (string, error) {
+	return s.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the SIGABA.
+func (s *SIGABA) Decrypt(ciphertext string) (string, error) {
+	return s.machine.Decrypt(ciphertext)
+}
+
+// Reset returns the rotors to the positions they were in when the adapter was created.
+func (s *SIGABA) Reset() {
+	_ = s.Restore(s.start)
+}
+
+// State returns the current position of every rotor.
+func (s *SIGABA) State() State {
+	var state sigabaState
+	for i, rotor := range s.rotors() {
+		state[i] = rotor.GetShownPos()
+	}
+	return state
+}
+
+// Restore returns the rotors to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a SIGABA adapter.
+func (s *SIGABA) Restore(state State) error {
+	positions, ok := state.(sigabaState)
+	if !ok {
+		return errors.New("state is not a SIGABA state")
+	}
+	for i, rotor := range s.rotors() {
+		rotor.SetShownPos(positions[i])
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package machine
+
+import (
+	"EnigmaLorenz/pkg/typex"
+	"errors"
+)
+
+// typexState holds the shown position of each rotor and stator of a Typex.
+type typexState [5]byte
+
+// A Typex adapts a typex.Typex to the Machine interface.
+type Typex struct {
+	machine typex.Typex
+	start   typexState
+}
+
+// NewTypex returns a Machine using the given Typex, remembering its current rotor positions for Reset.
+func NewTypex(machine typex.Typex) *Typex {
+	t := &Typex{machine: machine}
+	t.start = t.State().(typexState)
+	return t
+}
+
+// Name returns "Typex".
+func (t *Typex) Name() string {
+	return "Typex"
+}
+
+// Alphabet returns the letters the Typex accepts.
+func (t *Typex) Alphabet() string {
+	return "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+}
+
+// Encrypt enciphers plaintext with the Typex.
+func (t *Typex) Encrypt(plaintext string) (string, error) {
+	return t.machine.Encrypt(plaintext)
+}
+
+// Decrypt deciphers ciphertext with the Typex, which as a reciprocal machine is the same as enciphering it.
+func (t *Typex) Decrypt(ciphertext string) (string, error) {
+	return t.machine.Encrypt(ciphertext)
+}
+
+// Reset returns the rotors to the positions they were in when the adapter was created.
+func (t *Typex) Reset() {
+	_ = t.Restore(t.start)
+}
+
+// State returns the current rotor and stator positions.
+func (t *Typex) State() State {
+	return typexState{
+		t.machine.LeftRotor.GetShownPos(),
+		t.machine.CenterRotor.GetShownPos(),
+		t.machine.RightRotor.GetShownPos(),
+		t.machine.LeftStator.GetShownPos(),
+		t.machine.RightStator.GetShownPos(),
+	}
+}
+
+// Restore returns the rotors to positions saved by State.
+//
+// # Errors
+//
+// An error is returned if the State was not produced by a Typex adapter.
+func (t *Typex) Restore(state State) error {
+	s, ok := state.(typexState)
+	if !ok {
+		return errors.New("state is not a Typex state")
+	}
+	t.machine.LeftRotor.SetShownPos(s[0])
+	t.machine.CenterRotor.SetShownPos(s[1])
+	t.machine.RightRotor.SetShownPos(s[2])
+	t.machine.LeftStator.SetShownPos(s[3])
+	t.machine.RightStator.SetShownPos(s[4])
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package nema simulates the Swiss NEMA (Neue Maschine), an Enigma derivative built to replace the Swiss Army's Enigma K.
+//
+// The NEMA's drum holds ten wheels. From left to right these are the reflector (UKW), which turns during use,
+// then four contact wheels, each followed by a red notch wheel, and finally a red drive wheel.
+// The contact wheels and reflector are enigma.Rotor values wired as on the Enigma,
+// while the red wheels carry only notches and decide when the contact wheels move.
+//
+// This is a simplified NEMA rather than a faithful one. The real machine drives its wheels as a chain,
+// and that drive is not modelled: here every red wheel turns with every key, so each contact wheel moves in a fixed cycle
+// set by its own red wheel alone. Nor are the wheel sets the published Swiss ones: the wiring and notches held in
+// training.yaml and operational.yaml are made up.
+// The simulator will not reproduce traffic from a real NEMA.
+package nema
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"errors"
+)
+
+// A NEMA is the representation of the ten wheels in the drum.
+// ContactWheels and RedWheels are listed from left to right, so ContactWheels[i] is driven by RedWheels[i] on its right.
+type NEMA struct {
+	UKW           enigma.Rotor
+	ContactWheels [4]enigma.Rotor
+	RedWheels     [4]NotchWheel
+	DriveWheel    NotchWheel
+}
+
+// step advances the drum before a key is enciphered.
+//
+// The red wheels and the drive wheel turn with every key. Each contact wheel steps when the red wheel on its right
+// is at a notch, and the reflector steps when the drive wheel is at a notch.
+// This simplifies the real drive chain, in which the wheels do not all turn with every key:
+// as the red wheels never stop, each contact wheel and the reflector repeat a fixed cycle independent of the others.
+func (machine *NEMA) step() {
+	for idx := range machine.ContactWheels {
+		if machine.RedWheels[idx].AtNotch() {
+			machine.ContactWheels[idx].Rotate()
+		}
+	}
+	if machine.DriveWheel.AtNotch() {
+		machine.UKW.Rotate()
+	}
+	for idx := range machine.RedWheels {
+		machine.RedWheels[idx].rotate()
+	}
+	machine.DriveWheel.rotate()
+}
+
+// Encrypt enciphers a plaintext string with the NEMA.
+// As the reflector makes the machine reciprocal, deciphering is done by enciphering the ciphertext from the same start.
+//
+// # Errors
+//
+// If the encryption cannot complete due to invalid characters then a non-fatal error is returned.
+func (machine *NEMA) Encrypt(plaintext string) (string, error) {
+	if !enigma.LatinAlphabet().Valid(plaintext) {
+		return "", errors.New("nema input must be capitalized ascii letters only")
+	}
+
+	var cipher []byte
+	for _, chr := range []byte(plaintext) {
+		chr = chr - byte('A')
+		machine.step()
+
+		for rotorIndex := len(machine.ContactWheels) - 1; rotorIndex >= 0; rotorIndex-- {
+			chr = machine.ContactWheels[rotorIndex].Translate(chr)
+		}
+		chr = machine.UKW.Translate(chr)
+		for _, rotor := range machine.ContactWheels {
+			chr = rotor.TranslateReverse(chr)
+		}
+
+		cipher = append(cipher, chr+byte('A'))
+	}
+	return string(cipher), nil
+}
@@ -0,0 +1,189 @@
+package nema
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// A NotchWheel is one of the NEMA's red wheels, which carry no wiring but have notches that drive the contact wheels.
+type NotchWheel struct {
+	Name    string
+	Notches []byte
+	pos     byte
+}
+
+// SetPos sets the letter (0-25 for A-Z) shown by the wheel.
+//
+// # Errors
+//
+// An error is returned if the position is not between 0 and 25.
+func (w *NotchWheel) SetPos(pos byte) error {
+	if pos > 25 {
+		return errors.New("notch wheel position must be between 0 and 25")
+	}
+	w.pos = pos
+	return nil
+}
+
+// GetPos returns the letter (0-25 for A-Z) shown by the wheel.
+func (w *NotchWheel) GetPos() byte {
+	return w.pos
+}
+
+// AtNotch returns whether a notch is at the sensing position.
+func (w *NotchWheel) AtNotch() bool {
+	for _, notch := range w.Notches {
+		if notch == w.pos {
+			return true
+		}
+	}
+	return false
+}
+
+// rotate moves the wheel on by one letter.
+func (w *NotchWheel) rotate() {
+	w.pos = (w.pos + 1) % 26
+}
+
+// A WheelSet is a set of wheels issued for the NEMA: contact wheels, red wheels and a reflector.
+// Any four contact wheels and five red wheels from a set may be placed in the drum.
+type WheelSet struct {
+	Name          string
+	ContactWheels []enigma.Rotor
+	RedWheels     []NotchWheel
+	UKW           enigma.Rotor
+}
+
+//go:embed training.yaml
+var trainingCatalog string
+
+//go:embed operational.yaml
+var operationalCatalog string
+
+// ParseWheelSet reads a WheelSet from a rotor catalog in either of the forms read by enigma.ParseCatalog,
+// loading it through an enigma.Registry. The catalog must hold exactly one reflector, which is the UKW.
+// Red wheels carry no wiring, so any rotor with straight-through wiring is taken as a red wheel with only its notches used,
+// and every other rotor is a contact wheel. Wheels are listed in the WheelSet in order of their names, shortest first,
+// so wheels numbered 1-23 are in numerical order.
+//
+// # Errors
+//
+// An error is returned if the catalog cannot be loaded, a wheel does not have 26 contacts,
+// or there is not exactly one reflector.
+func ParseWheelSet(name string, text string) (WheelSet, error) {
+	set := WheelSet{Name: name}
+	registry := enigma.NewRegistry()
+	if err := registry.Load(text); err != nil {
+		return set, err
+	}
+
+	reflectors := registry.ReflectorNames()
+	if len(reflectors) != 1 {
+		return set, fmt.Errorf("%s wheel set must have exactly one reflector", name)
+	}
+	set.UKW = registry.MustReflector(reflectors[0])
+	if len(set.UKW.Wires) != 26 {
+		return set, fmt.Errorf("reflector %s must have 26 contacts", set.UKW.Name)
+	}
+
+	names := registry.RotorNames()
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i]) < len(names[j]) || len(names[i]) == len(names[j]) && names[i] < names[j]
+	})
+	for _, wheelName := range names {
+		wheel := registry.MustRotor(wheelName)
+		if len(wheel.Wires) != 26 {
+			return set, fmt.Errorf("wheel %s must have 26 contacts", wheelName)
+		}
+		if isStraight(wheel.Wires) {
+			set.RedWheels = append(set.RedWheels, NotchWheel{Name: wheelName, Notches: wheel.TurnoverList})
+		} else {
+			wheel.Name = wheelName
+			set.ContactWheels = append(set.ContactWheels, wheel)
+		}
+	}
+	return set, nil
+}
+
+// isStraight returns whether wiring connects every contact to the contact of the same number.
+func isStraight(wires []byte) bool {
+	for idx, wire := range wires {
+		if int(wire) != idx {
+			return false
+		}
+	}
+	return true
+}
+
+// TrainingWheels returns the training WheelSet held in training.yaml, with contact wheels 1-4 and red wheels 5-9,
+// so that every wheel of the set is used in the drum.
+func TrainingWheels() WheelSet {
+	return mustParseWheelSet("training", trainingCatalog)
+}
+
+// OperationalWheels returns the operational WheelSet held in operational.yaml, with contact wheels 11-16
+// and red wheels 17-23, from which four contact wheels and five red wheels are chosen by the key.
+func OperationalWheels() WheelSet {
+	return mustParseWheelSet("operational", operationalCatalog)
+}
+
+// mustParseWheelSet is like ParseWheelSet but panics if the catalog cannot be read, for the catalogs embedded in the package.
+func mustParseWheelSet(name string, text string) WheelSet {
+	set, err := ParseWheelSet(name, text)
+	if err != nil {
+		panic(fmt.Sprintf("%s wheel set: %s", name, err))
+	}
+	return set
+}
+
+// WheelSetNames returns the name of every WheelSet accepted by NewWheelSet.
+func WheelSetNames() []string {
+	return []string{"training", "operational"}
+}
+
+// NewWheelSet returns the WheelSet with the given name.
+//
+// # Errors
+//
+// An error is returned if the name is not a known WheelSet.
+func NewWheelSet(name string) (WheelSet, error) {
+	switch name {
+	case "training":
+		return TrainingWheels(), nil
+	case "operational":
+		return OperationalWheels(), nil
+	default:
+		return WheelSet{}, errors.New("wheel set must be training or operational")
+	}
+}
+
+// ContactWheel returns the contact wheel with the given name from the set.
+//
+// # Errors
+//
+// An error is returned if the set has no contact wheel with that name.
+func (set WheelSet) ContactWheel(name string) (enigma.Rotor, error) {
+	for _, wheel := range set.ContactWheels {
+		if wheel.Name == name {
+			return wheel, nil
+		}
+	}
+	return enigma.Rotor{}, fmt.Errorf("%s wheel set has no contact wheel %s", set.Name, name)
+}
+
+// RedWheel returns the red wheel with the given name from the set.
+//
+// # Errors
+//
+// An error is returned if the set has no red wheel with that name.
+func (set WheelSet) RedWheel(name string) (NotchWheel, error) {
+	for _, wheel := range set.RedWheels {
+		if wheel.Name == name {
+			return wheel, nil
+		}
+	}
+	return NotchWheel{}, fmt.Errorf("%s wheel set has no red wheel %s", set.Name, name)
+}
@@ -0,0 +1,59 @@
+package sigaba
+
+import "EnigmaLorenz/pkg/enigma"
+
+// RotorSet contains the ten 26 contact rotors, any of which may be used in the cipher or control bank,
+// and the five 10 contact index rotors.
+type RotorSet struct {
+	Rotors      [10]enigma.Rotor
+	IndexRotors [5]enigma.Rotor
+}
+
+// rotorWirings holds the wiring of each 26 contact rotor as the letters wired to A-Z.
+var rotorWirings = [10]string{
+	"YCHLQSUGBDIXNZKERPVJTAWFOM",
+	"INPXBWETGUYSAOCHVLDMQKZJFR",
+	"WNDRIOZPTAXHFJYQBMSVEKUCGL",
+	"TZGHOBKRVUXLQDMPNFWCJYEIAS",
+	"YWTAHRQJVLCEXUNGBIPZMSDFOK",
+	"QSLRBTEKOGAICFWYVMHJNXZUDP",
+	"CHJDQIGNBSAKVTUOXFWLEPRMZY",
+	"CDFAJXTIMNBEQHSUGRYLWZKVPO",
+	"XHFESZDNRBCGKQIJLTVMUOYAPW",
+	"EZJQXMOGYTCSFRIUPVNADLHWBK",
+}
+
+// indexWirings holds the wiring of each index rotor as the digits wired to 0-9.
+var indexWirings = [5]string{
+	"7591482630",
+	"3810592764",
+	"4086153297",
+	"3980526174",
+	"6497135280",
+}
+
+// GenerateRotors returns the RotorSet, with the rotors named by their number (0-9 and 0-4).
+func GenerateRotors() RotorSet {
+	set := RotorSet{}
+	for idx, wiring := range rotorWirings {
+		wires := make([]byte, len(wiring))
+		for i := range wires {
+			wires[i] = wiring[i] - 'A'
+		}
+		set.Rotors[idx] = enigma.Rotor{
+			Name:  string(rune('0' + idx)),
+			Wires: wires,
+		}
+	}
+	for idx, wiring := range indexWirings {
+		wires := make([]byte, len(wiring))
+		for i := range wires {
+			wires[i] = wiring[i] - '0'
+		}
+		set.IndexRotors[idx] = enigma.Rotor{
+			Name:  string(rune('0' + idx)),
+			Wires: wires,
+		}
+	}
+	return set
+}
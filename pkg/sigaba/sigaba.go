@@ -0,0 +1,152 @@
+// Package sigaba simulates the SIGABA, known to the US Navy as the ECM Mark II.
+//
+// The SIGABA enciphers with a bank of five cipher rotors, but unlike the Enigma the cipher rotors step irregularly.
+// For every letter, four contacts are energised on the input of a bank of five control rotors, which step like an odometer.
+// The outputs of the control rotors are gathered into nine lines feeding a bank of five index rotors that never step,
+// whose outputs are gathered into five lines, each stepping one of the cipher rotors.
+// Between one and four cipher rotors step with every letter.
+//
+// The rotors are enigma.Rotor values, with the 10 contact index rotors using the same type,
+// and any cipher or control rotor may be inserted reversed using enigma.Rotor.Reversed.
+// As on the machine, a reversed rotor turns the other way, so its letters count down rather than up as it steps.
+package sigaba
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"errors"
+	"strings"
+)
+
+// controlInputs holds the control bank inputs energised for every letter, the contacts F, G, H and I.
+var controlInputs = [4]byte{'F' - 'A', 'G' - 'A', 'H' - 'A', 'I' - 'A'}
+
+// controlToIndex holds the index bank input (1-9) fed by each control bank output A-Z.
+var controlToIndex = [26]byte{
+	9, 1, 2, 3, 3, 4, 4, 4, 5, 5, 5, 6, 6,
+	6, 6, 7, 7, 7, 7, 7, 8, 8, 8, 8, 8, 8,
+}
+
+// indexToCipher holds the cipher rotor (0-4) stepped by each index bank output 0-9.
+var indexToCipher = [10]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 0}
+
+// stepLetter is the letter a control rotor steps from when it carries the next slower rotor on with it.
+const stepLetter = 'O' - 'A'
+
+// A SIGABA is the representation of the cipher, control and index rotor banks, each listed from left to right.
+// Of the control rotors, the center rotor steps with every letter, the fourth steps as the center one passes O,
+// and the second steps as the fourth passes O, in whichever direction they turn.
+// The outer control rotors and the index rotors never step.
+type SIGABA struct {
+	CipherRotors  [5]enigma.Rotor
+	ControlRotors [5]enigma.Rotor
+	IndexRotors   [5]enigma.Rotor
+}
+
+// cipherSteps returns which cipher rotors will step for the current positions of the control and index rotors.
+func (machine *SIGABA) cipherSteps() [5]bool {
+	indexInputs := [10]bool{}
+	for _, chr := range controlInputs {
+		for rotorIndex := len(machine.ControlRotors) - 1; rotorIndex >= 0; rotorIndex-- {
+			chr = machine.ControlRotors[rotorIndex].Translate(chr)
+		}
+		indexInputs[controlToIndex[chr]] = true
+	}
+
+	steps := [5]bool{}
+	for input, active := range indexInputs {
+		if !active {
+			continue
+		}
+		chr := byte(input)
+		for _, rotor := range machine.IndexRotors {
+			chr = rotor.Translate(chr)
+		}
+		steps[indexToCipher[chr]] = true
+	}
+	return steps
+}
+
+// turn steps a cipher or control rotor by one letter, backwards if it was inserted reversed.
+func turn(rotor *enigma.Rotor) {
+	if rotor.IsReversed() {
+		rotor.RotateBackwards()
+	} else {
+		rotor.Rotate()
+	}
+}
+
+// step advances the cipher rotors chosen by the control and index banks, then steps the control rotors.
+func (machine *SIGABA) step() {
+	for idx, stepping := range machine.cipherSteps() {
+		if stepping {
+			turn(&machine.CipherRotors[idx])
+		}
+	}
+
+	fast, medium, slow := &machine.ControlRotors[2], &machine.ControlRotors[3], &machine.ControlRotors[1]
+	if fast.GetShownPos()-1 == stepLetter {
+		if medium.GetShownPos()-1 == stepLetter {
+			turn(slow)
+		}
+		turn(medium)
+	}
+	turn(fast)
+}
+
+// validLetters returns whether text only holds capital letters.
+func validLetters(text string) bool {
+	for _, chr := range text {
+		if chr < 'A' || chr > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Encrypt enciphers plaintext, passing each letter through the cipher rotors from left to right.
+// As on the machine, each Z is typed as an X and each space as a Z.
+//
+// # Errors
+//
+// An error is returned if the plaintext contains anything other than capital letters and spaces.
+func (machine *SIGABA) Encrypt(plaintext string) (string, error) {
+	plaintext = strings.ReplaceAll(strings.ReplaceAll(plaintext, "Z", "X"), " ", "Z")
+	if !validLetters(plaintext) {
+		return "", errors.New("sigaba plaintext must be capitalized ascii letters and spaces only")
+	}
+
+	cipher := make([]byte, len(plaintext))
+	for idx, chr := range []byte(plaintext) {
+		chr = chr - 'A'
+		for _, rotor := range machine.CipherRotors {
+			chr = rotor.Translate(chr)
+		}
+		cipher[idx] = chr + 'A'
+		machine.step()
+	}
+	return string(cipher), nil
+}
+
+// Decrypt deciphers ciphertext, passing each letter back through the cipher rotors from right to left.
+// Each Z in the result is printed as a space. Spaces in the ciphertext, such as between groups, are ignored.
+//
+// # Errors
+//
+// An error is returned if the ciphertext contains anything other than capital letters and spaces.
+func (machine *SIGABA) Decrypt(ciphertext string) (string, error) {
+	ciphertext = strings.ReplaceAll(ciphertext, " ", "")
+	if !validLetters(ciphertext) {
+		return "", errors.New("sigaba ciphertext must be capitalized ascii letters only")
+	}
+
+	plain := make([]byte, len(ciphertext))
+	for idx, chr := range []byte(ciphertext) {
+		chr = chr - 'A'
+		for rotorIndex := len(machine.CipherRotors) - 1; rotorIndex >= 0; rotorIndex-- {
+			chr = machine.CipherRotors[rotorIndex].TranslateReverse(chr)
+		}
+		plain[idx] = chr + 'A'
+		machine.step()
+	}
+	return strings.ReplaceAll(string(plain), "Z", " "), nil
+}
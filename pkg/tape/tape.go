@@ -0,0 +1,134 @@
+// Package tape renders 5 bit teleprinter codes as punched paper tape and parses textual tape dumps back into codes.
+//
+// A row of tape holds one code, with impulse 1 (the most significant bit, as used by lorenz.WheelsToByte) on the left.
+// The sprocket hole used to feed the tape sits between impulses 2 and 3.
+package tape
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sprocketAfter is the number of impulses punched before the sprocket hole in each row.
+const sprocketAfter = 2
+
+// punched returns whether the given impulse (1-5) of a code is punched.
+func punched(code byte, impulse int) bool {
+	return (code>>byte(5-impulse))&1 == 1
+}
+
+// Dump returns the codes as rows of dots and crosses, one row per code, where a cross is a punched hole.
+// e.g. the letter A (11000) is written as "xx...".
+func Dump(codes []byte) string {
+	var dump strings.Builder
+	for _, code := range codes {
+		for impulse := 1; impulse <= 5; impulse++ {
+			if punched(code, impulse) {
+				dump.WriteByte('x')
+			} else {
+				dump.WriteByte('.')
+			}
+		}
+		dump.WriteByte('\n')
+	}
+	return dump.String()
+}
+
+// RenderASCII returns the codes drawn as a length of punched tape running down the page.
+// Punched holes are drawn as 'o' and the sprocket holes as '.'.
+func RenderASCII(codes []byte) string {
+	var art strings.Builder
+	art.WriteString("+------+\n")
+	for _, code := range codes {
+		art.WriteByte('|')
+		for impulse := 1; impulse <= 5; impulse++ {
+			if punched(code, impulse) {
+				art.WriteByte('o')
+			} else {
+				art.WriteByte(' ')
+			}
+			if impulse == sprocketAfter {
+				art.WriteByte('.')
+			}
+		}
+		art.WriteString("|\n")
+	}
+	art.WriteString("+------+\n")
+	return art.String()
+}
+
+// RenderSVG returns the codes drawn as an SVG image of punched tape running from left to right.
+func RenderSVG(codes []byte) string {
+	const pitch = 10
+	const margin = 8
+	width := len(codes)*pitch + 2*margin
+	height := 6*pitch + 2*margin
+
+	var svg strings.Builder
+	_, _ = fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	_, _ = fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="#f3e6c4" stroke="#a89060"/>`+"\n", width, height)
+	for col, code := range codes {
+		x := margin + col*pitch + pitch/2
+		row := 0
+		for impulse := 1; impulse <= 5; impulse++ {
+			y := margin + row*pitch + pitch/2
+			if punched(code, impulse) {
+				_, _ = fmt.Fprintf(&svg, `<circle cx="%d" cy="%d" r="3.5" fill="#333"/>`+"\n", x, y)
+			}
+			row++
+			if impulse == sprocketAfter {
+				y = margin + row*pitch + pitch/2
+				_, _ = fmt.Fprintf(&svg, `<circle cx="%d" cy="%d" r="1.5" fill="#333"/>`+"\n", x, y)
+				row++
+			}
+		}
+	}
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+// Parse reads codes from a textual tape dump, one row per code.
+// Rows may be written as dots and crosses as produced by Dump, or as drawn by RenderASCII.
+// A punched hole may be written as 'x', 'X', 'o' or 'O' and an unpunched position as '.' or ' '.
+// Rows of 6 characters are taken to include the sprocket hole after the second impulse.
+// Blank lines and border lines starting with '+' are ignored.
+//
+// # Errors
+//
+// An error is returned if a row is not 5 or 6 characters long or contains any other characters.
+func Parse(dump string) ([]byte, error) {
+	codes := []byte{}
+	for lineNum, line := range strings.Split(dump, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "+") {
+			continue
+		}
+		row := strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") {
+			// Unpunched positions in ASCII art are spaces, so only the edges of the tape are removed.
+			row = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+		}
+
+		if len(row) == 6 {
+			row = row[:sprocketAfter] + row[sprocketAfter+1:]
+		}
+		if len(row) != 5 {
+			return []byte{}, fmt.Errorf("tape row %d must have 5 positions", lineNum+1)
+		}
+
+		code := byte(0)
+		for _, position := range row {
+			code <<= 1
+			switch position {
+			case 'x', 'X', 'o', 'O':
+				code |= 1
+			case '.', ' ':
+			default:
+				return []byte{}, errors.New("tape rows may only contain holes (x|o) and blanks (.| )")
+			}
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
@@ -0,0 +1,133 @@
+package typex
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//go:embed rotors.yaml
+var rotorCatalog string
+
+// ExampleReflector is the pairs of letters connected by the example reflector.
+const ExampleReflector = "AN BC FG IE KD LU MH OR TS VZ WQ XJ YP"
+
+// RotorSet contains example rotors A-H and an example reflector for the Typex.
+// Like service Typex rotors, each example rotor has several notches on its ring, between 5 and 9, and no two rings are alike.
+//
+// The rotors are not historical. The wiring of service Typex rotors was changed regularly and little of it survives,
+// so the wirings and notch rings held in rotors.yaml, and the example reflector, are made up for trying out the machine
+// and will not reproduce traffic from a real Typex.
+type RotorSet struct {
+	A         enigma.Rotor
+	B         enigma.Rotor
+	C         enigma.Rotor
+	D         enigma.Rotor
+	E         enigma.Rotor
+	F         enigma.Rotor
+	G         enigma.Rotor
+	H         enigma.Rotor
+	Reflector enigma.Rotor
+}
+
+// letterIndices converts a string of capital letters into their positions in the alphabet.
+func letterIndices(letters string) []byte {
+	indices := make([]byte, len(letters))
+	for idx := range indices {
+		indices[idx] = letters[idx] - 'A'
+	}
+	return indices
+}
+
+// GenerateRotors returns the example RotorSet, with the rotors held in rotors.yaml.
+func GenerateRotors() RotorSet {
+	registry := enigma.NewRegistry()
+	registry.MustLoad(rotorCatalog)
+	reflector, err := NewReflector(ExampleReflector)
+	if err != nil {
+		panic(fmt.Sprintf("example reflector: %s", err))
+	}
+	return RotorSet{
+		A:         registry.MustRotor("A"),
+		B:         registry.MustRotor("B"),
+		C:         registry.MustRotor("C"),
+		D:         registry.MustRotor("D"),
+		E:         registry.MustRotor("E"),
+		F:         registry.MustRotor("F"),
+		G:         registry.MustRotor("G"),
+		H:         registry.MustRotor("H"),
+		Reflector: reflector,
+	}
+}
+
+// Registry returns a Registry of the rotors A-H in the RotorSet, registered by their letters.
+// The reflector is not registered, as the Typex reflector is rewired by giving its pairs to NewReflector.
+func (set RotorSet) Registry() *enigma.Registry {
+	registry := enigma.NewRegistry()
+	for _, rotor := range []enigma.Rotor{set.A, set.B, set.C, set.D, set.E, set.F, set.G, set.H} {
+		_ = registry.AddRotor(rotor.Name, rotor)
+	}
+	return registry
+}
+
+// Reverse returns the rotor with its wired insert turned round in the ring, as Typex rotors could be inserted either way.
+// The notches are cut in the ring, so they are unchanged. The name of the reversed rotor has an R appended.
+func Reverse(r enigma.Rotor) enigma.Rotor {
+	return r.Reversed()
+}
+
+// NewReflector returns a reflector connecting the pairs of letters given, written as "AN BC ...".
+// Every letter must appear in exactly one of the 13 pairs.
+//
+// # Errors
+//
+// An error is returned if the pairs do not connect every letter to exactly one other.
+func NewReflector(pairs string) (enigma.Rotor, error) {
+	reflector := enigma.Rotor{Name: "Reflector", Wires: make([]byte, 26)}
+	connected := [26]bool{}
+	fields := strings.Fields(strings.ToUpper(pairs))
+	if len(fields) != 13 {
+		return reflector, errors.New("reflector must have 13 pairs of letters")
+	}
+	for _, pair := range fields {
+		if len(pair) != 2 || pair[0] < 'A' || pair[0] > 'Z' || pair[1] < 'A' || pair[1] > 'Z' || pair[0] == pair[1] {
+			return reflector, fmt.Errorf("reflector pair %s must be two different letters", pair)
+		}
+		a, b := pair[0]-'A', pair[1]-'A'
+		if connected[a] || connected[b] {
+			return reflector, fmt.Errorf("reflector pair %s uses a letter already connected", pair)
+		}
+		connected[a], connected[b] = true, true
+		reflector.Wires[a] = b
+		reflector.Wires[b] = a
+	}
+	return reflector, nil
+}
+
+// NewEntry returns the entry permutation given as the 26 letters wired to A-Z.
+// An empty string gives the straight-through entry, connecting each letter to itself.
+//
+// # Errors
+//
+// An error is returned if the letters are not a permutation of A-Z.
+func NewEntry(wiring string) (enigma.Rotor, error) {
+	if wiring == "" {
+		wiring = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	}
+	entry := enigma.Rotor{Name: "Entry"}
+	wiring = strings.ToUpper(wiring)
+	if len(wiring) != 26 {
+		return entry, errors.New("entry must wire all 26 letters")
+	}
+	seen := [26]bool{}
+	for _, letter := range []byte(wiring) {
+		if letter < 'A' || letter > 'Z' || seen[letter-'A'] {
+			return entry, errors.New("entry must be a permutation of the letters A-Z")
+		}
+		seen[letter-'A'] = true
+	}
+	entry.Wires = letterIndices(wiring)
+	return entry, nil
+}
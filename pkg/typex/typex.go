@@ -0,0 +1,87 @@
+// Package typex simulates the British Typex, which extended the Enigma design with five rotors, two of them stationary,
+// rotors with several notches and wired inserts that could be turned round in their rings.
+//
+// The rotors are enigma.Rotor values, so ring settings, window positions and notches work as they do on the Enigma.
+package typex
+
+import (
+	"errors"
+	"fmt"
+)
+import "EnigmaLorenz/pkg/enigma"
+
+// A Typex is the representation of the entry permutation, the five rotors and the reflector.
+// The left, center and right rotors step as on the Enigma, while the two stators on their right never move.
+// Signals pass from the keyboard through the Entry, the right then left stator, the moving rotors from right to left,
+// and the Reflector, before returning the same way.
+type Typex struct {
+	LeftRotor   enigma.Rotor
+	CenterRotor enigma.Rotor
+	RightRotor  enigma.Rotor
+	LeftStator  enigma.Rotor
+	RightStator enigma.Rotor
+	Reflector   enigma.Rotor
+	Entry       enigma.Rotor
+}
+
+// step advances the moving rotors as a single key press would, before the signal passes through them.
+func (machine *Typex) step() {
+	if machine.CenterRotor.AtNotch() {
+		machine.LeftRotor.Rotate()
+		machine.CenterRotor.Rotate()
+	}
+	if machine.RightRotor.AtNotch() {
+		machine.CenterRotor.Rotate()
+	}
+	machine.RightRotor.Rotate()
+}
+
+// Encrypt enciphers a plaintext string with the Typex.
+// As the reflector makes the machine reciprocal, deciphering is done by enciphering the ciphertext from the same start.
+// Letters are taken from the Alphabet the rotors are lettered with.
+//
+// # Errors
+//
+// If the encryption cannot complete due to characters not in the rotors' Alphabet,
+// or a rotor, entry or reflector lettered with a different Alphabet, then a non-fatal error is returned.
+func (machine *Typex) Encrypt(plaintext string) (string, error) {
+	alphabet := machine.RightRotor.Alphabet
+	if !alphabet.Valid(plaintext) {
+		if alphabet.Symbols() == enigma.LatinAlphabet().Symbols() {
+			return "", errors.New("typex input must be capitalized ascii letters only")
+		}
+		return "", fmt.Errorf("typex input must only contain the symbols %s", alphabet.Symbols())
+	}
+	parts := []enigma.Rotor{machine.Entry, machine.RightStator, machine.LeftStator,
+		machine.RightRotor, machine.CenterRotor, machine.LeftRotor, machine.Reflector}
+	for _, rotor := range parts {
+		if len(rotor.Wires) != alphabet.Size() {
+			return "", fmt.Errorf("rotor %s must have %d wires to match the alphabet", rotor.Name, alphabet.Size())
+		}
+		if rotor.Alphabet.Symbols() != alphabet.Symbols() {
+			return "", fmt.Errorf("rotor %s is lettered %s, not %s", rotor.Name, rotor.Alphabet.Symbols(), alphabet.Symbols())
+		}
+	}
+
+	var cipher []byte
+	for idx := range []byte(plaintext) {
+		chr, _ := alphabet.Index(plaintext[idx])
+		machine.step()
+
+		chr = machine.Entry.Translate(chr)
+		path := []enigma.Rotor{machine.RightStator, machine.LeftStator, machine.RightRotor, machine.CenterRotor, machine.LeftRotor}
+		for _, rotor := range path {
+			chr = rotor.Translate(chr)
+		}
+
+		chr = machine.Reflector.Translate(chr)
+
+		for rotorIndex := len(path) - 1; rotorIndex >= 0; rotorIndex-- {
+			chr = path[rotorIndex].TranslateReverse(chr)
+		}
+		chr = machine.Entry.TranslateReverse(chr)
+
+		cipher = append(cipher, alphabet.Symbol(chr))
+	}
+	return string(cipher), nil
+}
@@ -1,6 +1,9 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // NegMod is a helper function to have a mod n produce a positive result even if a is negative.
 func NegMod(a int, n int) int {
@@ -35,3 +38,47 @@ func ValidChars(text string, lorenzMode bool) bool {
 	}
 	return true
 }
+
+// SplitGroups splits text into groups of size symbols, the last of which may be short.
+// Symbols are counted as runes, so text in other scripts such as Cyrillic is not split inside a letter.
+// A size of 0 or less leaves the text whole as a single group.
+func SplitGroups(text string, size int) []string {
+	symbols := []rune(text)
+	groups := []string{}
+	if size <= 0 {
+		if text != "" {
+			groups = append(groups, text)
+		}
+		return groups
+	}
+	for start := 0; start < len(symbols); start += size {
+		end := start + size
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		groups = append(groups, string(symbols[start:end]))
+	}
+	return groups
+}
+
+// JoinGroups joins groups with spaces, starting a new line after every groupsPerLine groups if groupsPerLine is above 0.
+func JoinGroups(groups []string, groupsPerLine int) string {
+	var joined strings.Builder
+	for idx, group := range groups {
+		if idx > 0 {
+			if groupsPerLine > 0 && idx%groupsPerLine == 0 {
+				joined.WriteByte('\n')
+			} else {
+				joined.WriteByte(' ')
+			}
+		}
+		joined.WriteString(group)
+	}
+	return joined.String()
+}
+
+// FormatGroups splits text into groups of size symbols separated by spaces, as ciphertext was sent,
+// with groupsPerLine groups on each line. If groupsPerLine is 0 or less the groups are not wrapped. The last group may be short.
+func FormatGroups(text string, size int, groupsPerLine int) string {
+	return JoinGroups(SplitGroups(text, size), groupsPerLine)
+}
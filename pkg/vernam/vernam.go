@@ -0,0 +1,54 @@
+// Package vernam implements Gilbert Vernam's teleprinter cipher, which adds each ITA2 code of a message
+// to the next code of a key tape, impulse by impulse.
+//
+// With a truly random key tape used only once the cipher cannot be broken,
+// but two messages sent on the same key tape are in depth and can be read by adding one to the other.
+// The Lorenz machine imitated it by generating its key from wheels, avoiding the need to make and issue endless key tape.
+package vernam
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// GenerateKey returns n codes of random key tape chosen with crypto/rand.
+//
+// # Errors
+//
+// An error is returned if the random number generator fails.
+func GenerateKey(n int) ([]byte, error) {
+	key := make([]byte, n)
+	if _, err := rand.Read(key); err != nil {
+		return []byte{}, err
+	}
+	for idx := range key {
+		key[idx] &= 0x1f
+	}
+	return key, nil
+}
+
+// Encrypt adds the key tape to the codes of a message. Only as much key as the message needs is used.
+//
+// # Errors
+//
+// An error is returned if the key tape is shorter than the message.
+func Encrypt(codes []byte, key []byte) ([]byte, error) {
+	if len(key) < len(codes) {
+		return []byte{}, fmt.Errorf("key tape of %d codes is too short for a message of %d codes", len(key), len(codes))
+	}
+	cipher := make([]byte, len(codes))
+	for idx := range codes {
+		cipher[idx] = codes[idx] ^ key[idx]
+	}
+	return cipher, nil
+}
+
+// Decrypt removes the key tape from the codes of a ciphertext.
+// Adding the key a second time cancels it out, so this is the same as Encrypt.
+//
+// # Errors
+//
+// An error is returned if the key tape is shorter than the ciphertext.
+func Decrypt(cipher []byte, key []byte) ([]byte, error) {
+	return Encrypt(cipher, key)
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildCommand builds one of the commands in cmd into a temporary directory and returns the path of the binary.
+func buildCommand(t *testing.T, name string) string {
+	t.Helper()
+	binary := filepath.Join(t.TempDir(), name)
+	if output, err := exec.Command("go", "build", "-o", binary, "EnigmaLorenz/cmd/"+name).CombinedOutput(); err != nil {
+		t.Fatalf("building %s: %s\n%s", name, err, output)
+	}
+	return binary
+}
+
+func TestEnigmaCommandDefaultModel(t *testing.T) {
+	binary := buildCommand(t, "enigma")
+
+	// Without -model, a setup possible on either the Enigma I or the M3 is accepted, as before models were checked.
+	accepted := map[string][]string{
+		"reflector A":  {"-m", "HELLO", "-ukw", "A"},
+		"reflector B":  {"-m", "HELLO"},
+		"rotor VIII":   {"-m", "HELLO", "-l", "VIII 1 0"},
+		"fourth rotor": {"-m", "HELLO", "-f", "beta 1 0", "-ukw", "b"},
+	}
+	for name, args := range accepted {
+		if output, err := exec.Command(binary, args...).CombinedOutput(); err != nil {
+			t.Errorf("%s: unexpected error %s\n%s", name, err, output)
+		}
+	}
+
+	rejected := map[string][]string{
+		"rotor VIII with reflector A": {"-m", "HELLO", "-l", "VIII 1 0", "-ukw", "A"},
+		"reflector A on the M3":       {"-m", "HELLO", "-ukw", "A", "-model", "M3"},
+		"beta as the left rotor":      {"-m", "HELLO", "-l", "beta 1 0"},
+	}
+	for name, args := range rejected {
+		output, err := exec.Command(binary, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "Error for") {
+			t.Errorf("%s: expected the setup to be rejected, got %q", name, output)
+		}
+	}
+}
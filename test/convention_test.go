@@ -0,0 +1,71 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/convention"
+	"testing"
+)
+
+func TestConventionNormalize(t *testing.T) {
+	tests := []struct {
+		text       string
+		convention convention.Convention
+		expected   string
+	}{
+		{"Angriff um 0600 Uhr.", convention.Heer, "ANGRIFFUMNULLSEQSNULLNULLUHRX"},
+		{"Angriff um 0600 Uhr.", convention.Kriegsmarine, "ANGRIFFUMYPZPPYUHRX"},
+		{"Nachschub (Munition) fehlt, Verstärkung?", convention.Heer, "NAQSQUBYYMUNITIONYYFEHLTYVERSTAERKUNGUD"},
+		{"Nachschub (Munition) fehlt, Verstärkung?", convention.Kriegsmarine, "NAQSQUBJMUNITIONJFEHLTYVERSTAERKUNGUD"},
+		{"Straße: Köln's Süden", convention.Heer, "STRASSEXXKOELNSSUEDEN"},
+	}
+	for _, test := range tests {
+		normalized, err := convention.Normalize(test.text, test.convention)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if normalized != test.expected {
+			t.Errorf("%s %q: %s != %s", test.convention, test.text, normalized, test.expected)
+		}
+	}
+
+	if _, err := convention.Normalize("user@example", convention.Heer); err == nil {
+		t.Error("expected an error for a character with no convention")
+	}
+}
+
+func TestConventionRestore(t *testing.T) {
+	tests := []struct {
+		text       string
+		convention convention.Convention
+		expected   string
+	}{
+		{"ANGRIFFUMNULLSEQSNULLNULLUHRX", convention.Heer, "ANGRIFFUM0600UHR."},
+		{"ANGRIFFUMYPZPPYUHRX", convention.Kriegsmarine, "ANGRIFFUM0600UHR."},
+		{"YYMUNITIONYYFEHLTYVERSTAERKUNGUD", convention.Heer, "(MUNITION) FEHLT, VERSTAERKUNG?"},
+		{"JMUNITIONJFEHLTYQUELLEXXYQQY", convention.Kriegsmarine, "(MUNITION) FEHLT, QUELLE: 11"},
+	}
+	for _, test := range tests {
+		restored, err := convention.Restore(test.text, test.convention)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if restored != test.expected {
+			t.Errorf("%s %s: %q != %q", test.convention, test.text, restored, test.expected)
+		}
+	}
+
+	if _, err := convention.Restore("abc", convention.Heer); err == nil {
+		t.Error("expected an error for text that is not capital letters")
+	}
+}
+
+func TestParseConvention(t *testing.T) {
+	for idx, name := range convention.ConventionNames() {
+		parsed, err := convention.ParseConvention(name)
+		if err != nil || parsed != convention.Convention(idx) || parsed.String() != name {
+			t.Errorf("convention %s parsed as %s, %v", name, parsed, err)
+		}
+	}
+	if _, err := convention.ParseConvention("luftwaffe"); err == nil {
+		t.Error("expected an error for an unknown convention")
+	}
+}
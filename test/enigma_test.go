@@ -1,6 +1,9 @@
 package test
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 import "EnigmaLorenz/pkg/enigma"
 
 func TestRotorTranslateNoOffset(t *testing.T) {
@@ -140,3 +143,531 @@ func TestMachineEncryptRingPlugboard(t *testing.T) {
 		t.Errorf("Machine: %s, %s, %s, %s.\nPlaintext:\t\t\t%s.\nExpected Cipher:\t%s.\nActual Cipher:\t\t%s.\n", UKW_B.Name, III.Name, II.Name, I.Name, plaintext, expectedCipher, cipher)
 	}
 }
+
+func TestMachineSeek(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	for _, n := range []int{0, 1, 25, 26, 650, 1000, 16900, 40000} {
+		stepped := enigma.Enigma{
+			LeftRotor:   rotorSet.VI,
+			CenterRotor: rotorSet.II,
+			RightRotor:  rotorSet.VIII,
+			Reflector:   rotorSet.UKW_B,
+			Plugs:       enigma.NewPlugboard(),
+		}
+		sought := stepped
+		_, _ = stepped.Encrypt(strings.Repeat("A", n), false)
+		sought.Seek(n)
+
+		plaintext := "SEEKINGSHOULDMATCHSTEPPING"
+		expected, _ := stepped.Encrypt(plaintext, false)
+		cipher, _ := sought.Encrypt(plaintext, false)
+		if cipher != expected {
+			t.Errorf("Seek(%d) gives %s, stepping gives %s", n, cipher, expected)
+		}
+	}
+}
+
+func TestMachineStepBack(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.I,
+		CenterRotor: rotorSet.II,
+		RightRotor:  rotorSet.III,
+		Reflector:   rotorSet.UKW_B,
+		Plugs:       enigma.NewPlugboard(),
+	}
+	plaintext := strings.Repeat("THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", 30)
+	expected, _ := machine.Encrypt(plaintext, false)
+	for i := 0; i < len(plaintext); i++ {
+		if err := machine.StepBack(); err != nil {
+			t.Fatalf("unexpected error stepping back from key press %d: %s", len(plaintext)-i, err)
+		}
+	}
+	cipher, _ := machine.Encrypt(plaintext, false)
+	if cipher != expected {
+		t.Errorf("stepping back did not return the rotors to the start.\nExpected:\t%s\nActual:\t\t%s", expected, cipher)
+	}
+}
+
+func TestMachineStepBackUnreachable(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	center := rotorSet.II
+	// Rotor II's notch is at E, its fifth position.
+	center.SetShownPos(5)
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.I,
+		CenterRotor: center,
+		RightRotor:  rotorSet.III,
+		Reflector:   rotorSet.UKW_B,
+	}
+	if err := machine.StepBack(); err == nil {
+		t.Errorf("expected error stepping back with the center rotor set onto its notch")
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	if _, err := enigma.NewAlphabet("repeat", "ABCA"); err == nil {
+		t.Errorf("expected error for a repeated symbol")
+	}
+	var zero enigma.Alphabet
+	if zero.Size() != 26 || !zero.Valid("HELLO") || zero.Valid("HELLO1") {
+		t.Errorf("zero Alphabet should be the letters A-Z")
+	}
+	digits := enigma.DigitAlphabet()
+	if idx, ok := digits.Index('7'); !ok || idx != 7 || digits.Symbol(3) != '3' {
+		t.Errorf("incorrect digit alphabet indices")
+	}
+	if _, err := enigma.NewRotor("bad", digits, "0123456788", ""); err == nil {
+		t.Errorf("expected error for a rotor that is not a permutation")
+	}
+}
+
+func TestZ30Machine(t *testing.T) {
+	rotorSet := enigma.GenerateZ30Rotors()
+	newMachine := func() enigma.Enigma {
+		m := enigma.Enigma{
+			LeftRotor:   rotorSet.I,
+			CenterRotor: rotorSet.II,
+			RightRotor:  rotorSet.III,
+			Reflector:   rotorSet.UKW,
+			Plugs:       enigma.NewPlugboardFor(enigma.DigitAlphabet()),
+			Alphabet:    enigma.DigitAlphabet(),
+		}
+		m.LeftRotor.SetShownPos(4)
+		m.CenterRotor.SetRingSetting(9)
+		m.Plugs.AddPlug('1', '5')
+		return m
+	}
+
+	plaintext := "31415926535897932384626433832795"
+	encrypting := newMachine()
+	cipher, err := encrypting.Encrypt(plaintext, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Trim(cipher, "0123456789") != "" {
+		t.Errorf("Z30 ciphertext %s should only hold digits", cipher)
+	}
+	for idx := range cipher {
+		if cipher[idx] == plaintext[idx] {
+			t.Errorf("Digit %c enciphered to itself at %d", plaintext[idx], idx)
+		}
+	}
+	decrypting := newMachine()
+	if plain, _ := decrypting.Encrypt(cipher, false); plain != plaintext {
+		t.Errorf("Expected %s, got %s", plaintext, plain)
+	}
+
+	if _, err := encrypting.Encrypt("ABC", false); err == nil {
+		t.Errorf("expected error for letters on a Z30")
+	}
+	mixed := newMachine()
+	mixed.RightRotor = enigma.GenerateRotors().I
+	if _, err := mixed.Encrypt("123", false); err == nil {
+		t.Errorf("expected error for a 26 letter rotor on a Z30")
+	}
+	letters, _ := enigma.NewAlphabet("letters", "ABCDEFGHIJ")
+	mixed = newMachine()
+	mixed.RightRotor, _ = enigma.NewRotor("letters", letters, "GEBICHKADJ", "J")
+	if _, err := mixed.Encrypt("123", false); err == nil {
+		t.Errorf("expected error for a rotor lettered with another alphabet on a Z30")
+	}
+
+	registry := enigma.StandardRegistry()
+	rotor, err := registry.Rotor("Z30-II")
+	if err != nil || rotor.Alphabet.Symbols() != "0123456789" {
+		t.Errorf("registry rotor Z30-II should be lettered with the digits 0-9")
+	}
+	if problems := enigma.EnigmaZ30().Check(newMachine(), false); len(problems) != 1 || problems[0].Part != "plugboard" {
+		t.Errorf("expected only the plug to be a problem on a Z30, got %v", problems)
+	}
+}
+
+func TestRegistryStandardRotors(t *testing.T) {
+	registry := enigma.StandardRegistry()
+	rotorSet := enigma.GenerateRotors()
+
+	rotor, err := registry.Rotor("VI")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rotor.Name != rotorSet.VI.Name || string(rotor.Wires) != string(rotorSet.VI.Wires) {
+		t.Errorf("registry rotor VI is %s, expected %s", rotor.Name, rotorSet.VI.Name)
+	}
+	reflector, err := registry.Reflector("b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if reflector.Name != rotorSet.UKW_b.Name {
+		t.Errorf("registry reflector b is %s, expected %s", reflector.Name, rotorSet.UKW_b.Name)
+	}
+
+	// Rotors are copies, so changing one does not change the registry.
+	rotor.Wires[0] = 0
+	again, _ := registry.Rotor("VI")
+	if again.Wires[0] != rotorSet.VI.Wires[0] {
+		t.Error("changing a looked up rotor changed the registry")
+	}
+
+	if _, err := registry.Rotor("IX"); err == nil {
+		t.Error("expected an error for an unknown rotor")
+	}
+	if _, err := registry.Reflector("I"); err == nil {
+		t.Error("expected an error for a rotor looked up as a reflector")
+	}
+	if names := strings.Join(registry.ReflectorNames(), " "); names != "A B C Z30-UKW b c" {
+		t.Errorf("reflector names %q != %q", names, "A B C Z30-UKW b c")
+	}
+}
+
+func TestRegistryLoadCatalog(t *testing.T) {
+	yaml := `rotors:
+  # Rotor I and UKW-B under new names
+  - name: my-I
+    wiring: EKMFLGDQVZNTOWYHXUSPAIBRCJ
+    notches: Q
+  - name: "my-B"
+    wiring: YRUHQSLDPXNGOKMIEBFZCWVJAT
+    reflector: true
+`
+	json := `{"rotors": [
+		{"name": "my-I", "wiring": "EKMFLGDQVZNTOWYHXUSPAIBRCJ", "notches": "Q"},
+		{"name": "my-B", "wiring": "YRUHQSLDPXNGOKMIEBFZCWVJAT", "reflector": true}
+	]}`
+
+	rotorSet := enigma.GenerateRotors()
+	for _, catalog := range []string{yaml, json} {
+		registry := enigma.StandardRegistry()
+		if err := registry.Load(catalog); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		rotor, err := registry.Rotor("my-I")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(rotor.Wires) != string(rotorSet.I.Wires) || string(rotor.TurnoverList) != string(rotorSet.I.TurnoverList) {
+			t.Errorf("catalog rotor my-I is not wired as rotor I")
+		}
+		reflector, err := registry.Reflector("my-B")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(reflector.Wires) != string(rotorSet.UKW_B.Wires) {
+			t.Errorf("catalog reflector my-B is not wired as UKW-B")
+		}
+	}
+}
+
+func TestRegistryLoadCatalogQuotedHash(t *testing.T) {
+	yaml := `rotors:
+  - name: "hash#rotor" # a comment after the value
+    alphabet: "AB#C"
+    wiring: '#CAB'
+    notches: "#"
+`
+	registry := enigma.NewRegistry()
+	if err := registry.Load(yaml); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rotor, err := registry.Rotor("hash#rotor")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if symbols := rotor.Alphabet.Symbols(); symbols != "AB#C" {
+		t.Errorf("alphabet %q != %q", symbols, "AB#C")
+	}
+	if len(rotor.TurnoverList) != 1 || rotor.TurnoverList[0] != 2 {
+		t.Errorf("turnover list %v != [2]", rotor.TurnoverList)
+	}
+}
+
+func TestRegistryLoadCatalogErrors(t *testing.T) {
+	catalogs := map[string]string{
+		"short wiring":       "- name: X\n  wiring: ABC\n",
+		"repeated wire":      "- name: X\n  wiring: AACDEFGHIJKLMNOPQRSTUVWXYZ\n",
+		"unpaired reflector": "- name: X\n  wiring: EKMFLGDQVZNTOWYHXUSPAIBRCJ\n  reflector: true\n",
+		"duplicate name":     "- name: I\n  wiring: EKMFLGDQVZNTOWYHXUSPAIBRCJ\n",
+		"unknown key":        "- name: X\n  colour: red\n",
+		"missing wiring":     `{"rotors": [{"name": "X"}]}`,
+		"bad json":           `{"rotors": [`,
+	}
+	for name, catalog := range catalogs {
+		if err := enigma.StandardRegistry().Load(catalog); err == nil {
+			t.Errorf("expected an error for a catalog with %s", name)
+		}
+	}
+}
+
+func TestRegistryMust(t *testing.T) {
+	registry := enigma.StandardRegistry()
+	if rotor := registry.MustRotor("I"); rotor.Name != "I" {
+		t.Errorf("rotor name %q != %q", rotor.Name, "I")
+	}
+	if reflector := registry.MustReflector("Z30-UKW"); len(reflector.Wires) != 10 {
+		t.Errorf("Z30 reflector has %d contacts, not 10", len(reflector.Wires))
+	}
+
+	panics := map[string]func(){
+		"bad catalog":       func() { enigma.NewRegistry().MustLoad("- name: X\n  wiring: ABC\n") },
+		"unknown rotor":     func() { registry.MustRotor("IX") },
+		"unknown reflector": func() { registry.MustReflector("I") },
+	}
+	for name, fn := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %s", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestRotorValidate(t *testing.T) {
+	if err := enigma.GenerateRotors().Validate(); err != nil {
+		t.Fatalf("standard rotor set is invalid: %s", err)
+	}
+	if err := enigma.GenerateZ30Rotors().UKW.ValidateReflector(); err != nil {
+		t.Fatalf("Z30 reflector is invalid: %s", err)
+	}
+
+	rotor := enigma.GenerateRotors().I
+	rotor.Wires = append([]byte{}, rotor.Wires...)
+	rotor.Wires[1] = rotor.Wires[0]
+	if err := rotor.Validate(); err == nil || err.Error() != "rotor I wires contacts A and B both to E" {
+		t.Errorf("unexpected error for a repeated wire: %v", err)
+	}
+
+	rotor = enigma.GenerateRotors().I
+	rotor.TurnoverList = []byte{26}
+	if err := rotor.Validate(); err == nil {
+		t.Error("expected an error for a turnover position off the rotor")
+	}
+
+	if err := (enigma.Rotor{Name: "empty"}).Validate(); err == nil {
+		t.Error("expected an error for a rotor with no wires")
+	}
+}
+
+func TestReflectorValidate(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	if err := rotorSet.I.ValidateReflector(); err == nil || !strings.Contains(err.Error(), "cannot have notches") {
+		t.Errorf("unexpected error for a reflector with notches: %v", err)
+	}
+	if err := rotorSet.Beta.ValidateReflector(); err == nil || err.Error() != "reflector Beta wires A to L but L back to B" {
+		t.Errorf("unexpected error for a reflector that is not an involution: %v", err)
+	}
+
+	identity := enigma.Rotor{Name: "identity", Wires: make([]byte, 26)}
+	for idx := range identity.Wires {
+		identity.Wires[idx] = byte(idx)
+	}
+	if err := identity.ValidateReflector(); err == nil || err.Error() != "reflector identity wires contact A to itself" {
+		t.Errorf("unexpected error for a reflector with a fixed point: %v", err)
+	}
+
+	rotorSet.UKW_C = identity
+	if err := rotorSet.Validate(); err == nil {
+		t.Error("expected an error for a rotor set with an invalid reflector")
+	}
+}
+
+func TestPlugboardValidate(t *testing.T) {
+	plugboard := enigma.NewPlugboard()
+	plugboard.AddPlug('A', 'B')
+	if err := plugboard.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	plugboard.AddPlug('C', 'C')
+	if err := plugboard.Validate(); err == nil || err.Error() != "plug joins C to itself" {
+		t.Errorf("unexpected error for a self plug: %v", err)
+	}
+}
+
+func TestMachineEncryptInvalidWiring(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.I,
+		CenterRotor: rotorSet.II,
+		RightRotor:  rotorSet.III,
+		Reflector:   rotorSet.Beta,
+		Plugs:       enigma.NewPlugboard(),
+	}
+	if _, err := machine.Encrypt("HELLO", false); err == nil {
+		t.Error("expected an error for a machine with an invalid reflector")
+	}
+}
+
+func TestModelCheckValid(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	plugboard := enigma.NewPlugboard()
+	plugboard.AddPlug('A', 'B')
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.VIII,
+		CenterRotor: rotorSet.II,
+		RightRotor:  rotorSet.IV,
+		FourthRotor: rotorSet.Gamma,
+		Reflector:   rotorSet.UKW_c,
+		Plugs:       plugboard,
+	}
+	if problems := enigma.EnigmaM4().Check(machine, true); len(problems) != 0 {
+		t.Errorf("unexpected problems for a valid M4: %v", problems)
+	}
+
+	machine.Reflector = rotorSet.UKW_B
+	if problems := enigma.EnigmaM3().Check(machine, false); len(problems) != 0 {
+		t.Errorf("unexpected problems for a valid M3: %v", problems)
+	}
+}
+
+func TestModelCheckProblems(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	plugboard := enigma.NewPlugboard()
+	for _, pair := range []string{"AB", "CD", "EF", "GH", "IJ", "KL", "MN", "OP", "QR", "ST", "UV"} {
+		plugboard.AddPlug(pair[0], pair[1])
+	}
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.Beta,
+		CenterRotor: rotorSet.VI,
+		RightRotor:  rotorSet.VI,
+		FourthRotor: rotorSet.Gamma,
+		Reflector:   rotorSet.UKW_b,
+		Plugs:       plugboard,
+	}
+	problems := enigma.EnigmaI().Check(machine, true)
+	expected := []string{
+		"left rotor: rotor Beta can only be used as the fourth rotor",
+		"center rotor: rotor VI was not issued with the Enigma I",
+		"right rotor: rotor VI was not issued with the Enigma I",
+		"right rotor: rotor VI is already used as the center rotor",
+		"fourth rotor: the Enigma I has no fourth rotor slot",
+		"reflector: reflector Narrow UKW-b cannot be used with the Enigma I, it must be one of UKW-A|UKW-B|UKW-C",
+		"plugboard: 11 plugs are used but the Enigma I was issued with 10 cables",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("got %d problems, expected %d: %v", len(problems), len(expected), problems)
+	}
+	for idx, problem := range problems {
+		if problem.Error() != expected[idx] {
+			t.Errorf("%q != %q", problem.Error(), expected[idx])
+		}
+	}
+}
+
+func TestModelCheckFourthRotor(t *testing.T) {
+	rotorSet := enigma.GenerateRotors()
+	machine := enigma.Enigma{
+		LeftRotor:   rotorSet.I,
+		CenterRotor: rotorSet.II,
+		RightRotor:  rotorSet.III,
+		FourthRotor: rotorSet.IV,
+		Reflector:   rotorSet.UKW_B,
+	}
+	problems := enigma.EnigmaM4().Check(machine, true)
+	if len(problems) != 2 || problems[0].Part != "fourth rotor" || problems[1].Part != "reflector" {
+		t.Errorf("expected fourth rotor and reflector problems, got %v", problems)
+	}
+	if problems := enigma.EnigmaM4().Check(machine, false); len(problems) != 2 || problems[0].Detail != "the Enigma M4 needs a fourth rotor" {
+		t.Errorf("expected a missing fourth rotor problem, got %v", problems)
+	}
+	if _, err := enigma.NewModel("M5"); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+}
+
+func TestFormatGroups(t *testing.T) {
+	formatted := enigma.FormatGroups("ABCDEFGHIJKLMNOPQRSTUVW", 5, 2)
+	expected := "ABCDE FGHIJ\nKLMNO PQRST\nUVW"
+	if formatted != expected {
+		t.Errorf("%q != %q", formatted, expected)
+	}
+	if formatted := enigma.FormatGroups("ABCDEFGH", 4, 0); formatted != "ABCD EFGH" {
+		t.Errorf("%q != %q", formatted, "ABCD EFGH")
+	}
+}
+
+func TestMessageHeerRoundTrip(t *testing.T) {
+	message := enigma.Message{
+		From:       "C",
+		To:         "U6Z",
+		Time:       "1510",
+		Indicator:  "EHZ TBS",
+		Kenngruppe: "XYABC",
+		Text:       "TVEXSQBLTWLDAHHYEOEFPTWYBLENDPMKOXLDFAMUDWIJDXRJZ",
+	}
+	formatted, err := message.Format(enigma.LayoutHeer, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "U6Z DE C 1510 = 54 = EHZ TBS =\nXYABC TVEXS QBLTW LDAHH YEOEF\nPTWYB LENDP MKOXL DFAMU DWIJD\nXRJZ"
+	if formatted != expected {
+		t.Fatalf("%q != %q", formatted, expected)
+	}
+
+	parsed, layout, err := enigma.ParseMessage(strings.ToLower(formatted)+" =", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if layout != enigma.LayoutHeer || parsed != message {
+		t.Errorf("parsed %s message %+v, expected %+v", layout, parsed, message)
+	}
+
+	// Without a Kenngruppe the first group is part of the text.
+	parsed, _, _ = enigma.ParseMessage(formatted, false)
+	if parsed.Text != message.Kenngruppe+message.Text {
+		t.Errorf("%s != %s", parsed.Text, message.Kenngruppe+message.Text)
+	}
+}
+
+func TestMessageKriegsmarineRoundTrip(t *testing.T) {
+	message := enigma.Message{From: "M", Time: "0930", Indicator: "QWER", Text: "OPCILLAZFXL"}
+	formatted, err := message.Format(enigma.LayoutKriegsmarine, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "M 0930 = 19 =\nQWER OPCI LLAZ FXL QWER"
+	if formatted != expected {
+		t.Fatalf("%q != %q", formatted, expected)
+	}
+	parsed, layout, err := enigma.ParseMessage(formatted, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if layout != enigma.LayoutKriegsmarine || parsed != message {
+		t.Errorf("parsed %s message %+v, expected %+v", layout, parsed, message)
+	}
+}
+
+func TestParseMessageErrors(t *testing.T) {
+	intercepts := map[string]string{
+		"wrong letter count":   "U6Z DE C 1510 = 12 = EHZ TBS =\nABCDE FGHIJ",
+		"missing indicator":    "U6Z DE C 1510 = 10 =\nABCDE FGHIJ",
+		"unrepeated indicator": "M 0930 = 12 =\nQWER ABCD EFGH",
+		"digits in text":       "ABCDE 12345",
+	}
+	for name, intercept := range intercepts {
+		if _, _, err := enigma.ParseMessage(intercept, false); err == nil {
+			t.Errorf("expected an error for an intercept with %s", name)
+		}
+	}
+
+	parsed, layout, err := enigma.ParseMessage("abcde fghij\nklm", false)
+	if err != nil || layout != enigma.LayoutGroups || parsed.Text != "ABCDEFGHIJKLM" {
+		t.Errorf("unexpected result for groups without a header: %+v %s %v", parsed, layout, err)
+	}
+}
+
+func TestMessageFormatErrors(t *testing.T) {
+	message := enigma.Message{From: "C", To: "U6Z", Time: "15:10", Indicator: "EHZ TBS", Text: "ABC"}
+	if _, err := message.Format(enigma.LayoutHeer, 10); err == nil {
+		t.Error("expected an error for a badly formed time")
+	}
+	message.Time = "1510"
+	if _, err := message.Format(enigma.LayoutKriegsmarine, 10); err == nil {
+		t.Error("expected an error for a heer indicator in the kriegsmarine layout")
+	}
+	if _, err := enigma.ParseLayout("luftwaffe"); err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/fialka"
+	"strings"
+	"testing"
+)
+
+func newTestFialka(keyboard fialka.Keyboard) fialka.Fialka {
+	rotors := fialka.GenerateRotors()
+	rotors[3] = rotors[3].Reversed()
+	card := fialka.IdentityCard()
+	card.Entry[0], card.Entry[7] = card.Entry[7], card.Entry[0]
+	return fialka.Fialka{
+		Rotors:    rotors,
+		Reflector: fialka.DefaultReflector(),
+		Card:      card,
+		Keyboard:  keyboard,
+	}
+}
+
+func TestFialkaCyrillic(t *testing.T) {
+	encrypting := newTestFialka(fialka.CyrillicKeyboard())
+	cipher, err := encrypting.Encrypt("ЁЖИКЙЪ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypting := newTestFialka(fialka.CyrillicKeyboard())
+	if plain, _ := decrypting.Encrypt(cipher); plain != "ЕЖИКИЬ" {
+		t.Errorf("Expected ЕЖИКИЬ with Ё, Й and Ъ folded, got %s", plain)
+	}
+	if _, err := encrypting.Encrypt("HELLO"); err == nil {
+		t.Errorf("expected error for Latin letters in Cyrillic mode")
+	}
+}
+
+func TestFialkaStepping(t *testing.T) {
+	m := newTestFialka(fialka.LatinKeyboard())
+	_, _ = m.Encrypt("A")
+	if m.Rotors[0].GetShownPos() != 2 {
+		t.Errorf("First rotor should step forwards with every key, at %d", m.Rotors[0].GetShownPos())
+	}
+	_, _ = m.Encrypt(strings.Repeat("A", 59))
+	if m.Rotors[0].GetShownPos() != 1 {
+		t.Errorf("First rotor should return to 1 after 60 keys, at %d", m.Rotors[0].GetShownPos())
+	}
+	stepped := false
+	for _, rotor := range m.Rotors[1:] {
+		stepped = stepped || rotor.GetShownPos() != 1
+	}
+	if !stepped {
+		t.Errorf("Blocking pins never stepped any rotor after the first")
+	}
+
+	// With every blocking pin raised on the first rotor, the second steps backwards with every key.
+	m = newTestFialka(fialka.LatinKeyboard())
+	m.Rotors[0].TurnoverList = make([]byte, fialka.Contacts)
+	for pos := range m.Rotors[0].TurnoverList {
+		m.Rotors[0].TurnoverList[pos] = byte(pos)
+	}
+	_, _ = m.Encrypt("AB")
+	if m.Rotors[1].GetShownPos() != 29 {
+		t.Errorf("Second rotor should step backwards to 29 after 2 keys, at %d", m.Rotors[1].GetShownPos())
+	}
+}
+
+func TestFialkaSettings(t *testing.T) {
+	card := fialka.IdentityCard()
+	card.Entry[2], card.Entry[29] = 29, 2
+	parsed, err := fialka.ParseCard(card.Format())
+	if err != nil || parsed != card {
+		t.Errorf("Card did not survive formatting:\n%s", card.Format())
+	}
+	if _, err := fialka.ParseCard(strings.Replace(card.Format(), "O", ".", 1)); err == nil {
+		t.Errorf("expected error for a card row without a hole")
+	}
+
+	wires := fialka.DefaultReflector().Wires
+	broken := append([]byte{}, wires...)
+	broken[wires[3]] = broken[wires[3]] ^ 1
+	if _, err := fialka.NewReflector(broken); err == nil {
+		t.Errorf("expected error for a reflector that does not pair contacts")
+	}
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/hebern"
+	"strings"
+	"testing"
+)
+
+func newTestHebern() hebern.Hebern {
+	rotorSet := enigma.GenerateRotors()
+	return hebern.NewFiveRotor([5]enigma.Rotor{rotorSet.I, rotorSet.II, rotorSet.III, rotorSet.IV, rotorSet.V})
+}
+
+func TestHebernNotReciprocal(t *testing.T) {
+	plaintext := strings.Repeat("THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", 30)
+	encrypting := newTestHebern()
+	cipher, err := encrypting.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a reflector, enciphering the ciphertext again does not give back the plaintext,
+	// and some letters are enciphered to themselves.
+	again := newTestHebern()
+	if twice, _ := again.Encrypt(cipher); twice == plaintext {
+		t.Errorf("Hebern should not be reciprocal")
+	}
+	self := 0
+	for idx := range cipher {
+		if cipher[idx] == plaintext[idx] {
+			self++
+		}
+	}
+	if self == 0 {
+		t.Errorf("Expected some letters to be enciphered to themselves without a reflector")
+	}
+}
+
+func TestHebernStepping(t *testing.T) {
+	m := newTestHebern()
+	// Rotor V has its notch at Z, so the fourth rotor first steps as the right rotor leaves Z.
+	_, _ = m.Encrypt(strings.Repeat("A", 26))
+	if m.Rotors[4].GetShownPos() != 1 || m.Rotors[3].GetShownPos() != 2 || m.Rotors[2].GetShownPos() != 1 {
+		t.Errorf("Expected rotors at 1 1 1 2 1 after 26 letters, got %d %d %d %d %d",
+			m.Rotors[0].GetShownPos(), m.Rotors[1].GetShownPos(), m.Rotors[2].GetShownPos(),
+			m.Rotors[3].GetShownPos(), m.Rotors[4].GetShownPos())
+	}
+}
+
+func TestHebernAlphabet(t *testing.T) {
+	z30 := enigma.GenerateZ30Rotors()
+	machine := hebern.NewSingleRotor(z30.I)
+	cipher, err := machine.Encrypt("0123456789")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	decrypting := hebern.NewSingleRotor(enigma.GenerateZ30Rotors().I)
+	if plain, err := decrypting.Decrypt(cipher); err != nil || plain != "0123456789" {
+		t.Errorf("%q != %q (%v)", plain, "0123456789", err)
+	}
+	digits := hebern.NewSingleRotor(z30.I)
+	if _, err := digits.Encrypt("ABC"); err == nil {
+		t.Error("expected an error for letters on a digit rotor")
+	}
+
+	mixed := hebern.NewFiveRotor([5]enigma.Rotor{z30.I, z30.II, z30.III, z30.I, enigma.GenerateRotors().I})
+	if _, err := mixed.Encrypt("123"); err == nil {
+		t.Error("expected an error for rotors lettered with different alphabets")
+	}
+}
@@ -36,3 +36,120 @@ func TestReverse(t *testing.T) {
 		t.Errorf("%s != %s", start, str)
 	}
 }
+
+func TestITA2MSB(t *testing.T) {
+	lsb := lorenz.NewITA2LSB()
+	msb := lorenz.NewITA2MSB()
+	ita, _ := msb.AsciiToITA2("ETO", false)
+	// E, T and O are 10000, 00001 and 00011 in NewITA2LSB
+	expected := []byte{0x01, 0x10, 0x18}
+	if !bytes.Equal(ita, expected) {
+		t.Errorf("%x != %x", ita, expected)
+	}
+	str, _ := lsb.ITA2ToAscii([]byte{0x10, 0x01, 0x03}, true)
+	if str != "ETO" {
+		t.Errorf("%s != ETO", str)
+	}
+}
+
+func TestUSTTYFigures(t *testing.T) {
+	ustty := lorenz.NewUSTTY()
+	ita2 := lorenz.NewITA2LSB()
+	usCodes, _ := ustty.AsciiToITA2("$;1", false)
+	ita2Codes, _ := ita2.AsciiToITA2("#=1", false)
+	if !bytes.Equal(usCodes, ita2Codes) {
+		t.Errorf("US TTY figures should share codes with ITA2 figures. %x != %x", usCodes, ita2Codes)
+	}
+}
+
+func TestBletchleyNotation(t *testing.T) {
+	notation := lorenz.NewBletchleyNotation()
+	ita2 := lorenz.NewITA2LSB()
+	codes, _ := ita2.AsciiToITA2("N 5", false)
+	str, _ := notation.ITA2ToAscii(codes, false)
+	// N, space, figure shift, 5 (T in letter shift)
+	if str != "N95T" {
+		t.Errorf("%s != N95T", str)
+	}
+	roundTrip, _ := notation.AsciiToITA2(str, false)
+	if !bytes.Equal(roundTrip, codes) {
+		t.Errorf("%x != %x", roundTrip, codes)
+	}
+}
+
+func TestNewAlphabet(t *testing.T) {
+	for _, name := range lorenz.AlphabetNames() {
+		alphabet, err := lorenz.NewAlphabet(name)
+		if err != nil {
+			t.Errorf("alphabet %s could not be created: %s", name, err)
+		}
+		if alphabet.Name != name {
+			t.Errorf("alphabet %s has name %s", name, alphabet.Name)
+		}
+	}
+	if _, err := lorenz.NewAlphabet("morse"); err == nil {
+		t.Errorf("expected error for unknown alphabet")
+	}
+}
+
+func TestEncoderDecoderShiftCodesInCipher(t *testing.T) {
+	alphabet := lorenz.NewITA2LSB()
+	encoder := lorenz.NewEncoder(alphabet)
+	decoder := lorenz.NewDecoder(alphabet)
+	plaintext := "ATTACK AT 0600"
+	codes, _ := encoder.Encode(plaintext)
+	// The key turns the first two codes into figure shift and letter shift.
+	key := []byte{0x03, 0x1e}
+	for i := range key {
+		codes[i] ^= key[i]
+	}
+	// Ciphertext containing shift codes must survive being written as binary.
+	cipher, err := lorenz.ParseBinary(lorenz.FormatBinary(codes))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := range key {
+		cipher[i] ^= key[i]
+	}
+	str, _ := decoder.Decode(cipher)
+	if str != plaintext {
+		t.Errorf("%s != %s", str, plaintext)
+	}
+}
+
+func TestEncoderStateAcrossCalls(t *testing.T) {
+	encoder := lorenz.NewEncoder(lorenz.NewITA2LSB())
+	first, _ := encoder.Encode("A1")
+	second, _ := encoder.Encode("2")
+	if len(first) != 3 || len(second) != 1 {
+		t.Errorf("figure shift should carry across calls. First: %x, Second: %x", first, second)
+	}
+	if encoder.InLetterShift() {
+		t.Errorf("encoder should be left in figure shift")
+	}
+}
+
+func TestUnshiftOnSpace(t *testing.T) {
+	alphabet := lorenz.NewUSTTY()
+	encoder := lorenz.NewEncoder(alphabet)
+	encoder.UnshiftOnSpace = true
+	codes, _ := encoder.Encode("1 A")
+	// Figure shift, 1, space, A with no letter shift needed after the space
+	if len(codes) != 4 {
+		t.Errorf("expected 4 codes, got %x", codes)
+	}
+	decoder := lorenz.NewDecoder(alphabet)
+	decoder.UnshiftOnSpace = true
+	str, _ := decoder.Decode(codes)
+	if str != "1 A" {
+		t.Errorf("%s != 1 A", str)
+	}
+}
+
+func TestParseBinaryInvalid(t *testing.T) {
+	for _, input := range []string{"1000", "10002", "100001"} {
+		if _, err := lorenz.ParseBinary(input); err == nil {
+			t.Errorf("expected error parsing %s", input)
+		}
+	}
+}
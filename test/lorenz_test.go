@@ -2,7 +2,10 @@ package test
 
 import (
 	"EnigmaLorenz/pkg/lorenz"
+	"bytes"
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +54,534 @@ func TestLorenzEncrypt(t *testing.T) {
 		t.Errorf("%s != %s", plaintext, decoded)
 	}
 }
+
+// chiRecovered returns which of the standard Chi wheels have their pattern, or its complement, in patterns.
+func chiRecovered(patterns [5][]bool) [5]bool {
+	var recovered [5]bool
+	for idx, wheel := range lorenz.NewWheelSet().Chi {
+		same, complement := true, true
+		for pin, val := range wheel.GetPins() {
+			if patterns[idx][pin] != val {
+				same = false
+			} else {
+				complement = false
+			}
+		}
+		recovered[idx] = same || complement
+	}
+	return recovered
+}
+
+func TestRecoverChiPatterns(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	alphabet := lorenz.NewITA2LSB()
+	// Plaintext heavy in repeated characters gives a strongly dot-biased ΔP, the best case for rectangling.
+	plaintext := strings.Repeat("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATTACK AT DAWN", 100)
+	encoded, _ := alphabet.AsciiToITA2(plaintext, false)
+	cipher := machine.Encrypt(encoded)
+
+	patterns, err := lorenz.RecoverChiPatterns(cipher)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for idx, recovered := range chiRecovered(patterns) {
+		if !recovered {
+			t.Errorf("chi wheel %d pattern not recovered.\nExpected:\t%v\nActual:\t\t%v", idx+1, wheels.Chi[idx].GetPins(), patterns[idx])
+		}
+	}
+}
+
+// militaryWords are the words used to make up German military traffic for testing rectangling.
+var militaryWords = strings.Fields(`AN OKH GEN.ST.D.H. OP.ABT. BETR.: LAGE HEERESGRUPPE SUED MITTE NORD AM FEINDLICHE ANGRIFFE MIT
+STARKEN PANZERKRAEFTEN BEI PZ.ARMEE ABGEWIESEN. FEINDPANZER ABGESCHOSSEN. EIGENE VERLUSTE: OFFZ., UFFZ. U. MANNSCH.
+MUNITIONSLAGE GESPANNT, ZUFUEHRUNG VON SCHUSS CM DRINGEND ERFORDERLICH. HGR. IA NR. G.KDOS. DIVISION REGIMENT
+BATAILLON KORPS ARMEE STELLUNG FRONT RAUM SUEDLICH NOERDLICH OESTLICH WESTLICH VORSTOSS GEGENANGRIFF
+ZURUECKGEWORFEN GEHALTEN VERSTAERKUNG EINGETROFFEN TREIBSTOFF VERPFLEGUNG LUFTWAFFE BOMBER JAEGER
+WETTER REGEN SCHNEE STRASSEN BRUECKE FLUSS DORF HOEHE KM UHR MELDUNG BEFEHL GEZ. CHEF`)
+
+// militaryText returns length characters of made-up German military traffic, words and numbers chosen from a fixed seed.
+func militaryText(length int) string {
+	rng := rand.New(rand.NewSource(1))
+	var text strings.Builder
+	for text.Len() < length {
+		if rng.Intn(5) == 0 {
+			for digits := 1 + rng.Intn(4); digits > 0; digits-- {
+				text.WriteByte("0123456789"[rng.Intn(10)])
+			}
+		} else {
+			text.WriteString(militaryWords[rng.Intn(len(militaryWords))])
+		}
+		text.WriteByte(' ')
+	}
+	return text.String()[:length]
+}
+
+func TestRecoverChiPatternsMilitaryText(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	encoder := lorenz.NewEncoder(lorenz.NewITA2LSB())
+	// 80000 characters of traffic are about what rectangling needs when ΔD1 + ΔD2 is only around 53% dots.
+	encoded, err := encoder.Encode(militaryText(80000))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	patterns, err := lorenz.RecoverChiPatterns(machine.Encrypt(encoded))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	recovered := chiRecovered(patterns)
+	// Chi 1 and 2 come from the Rectangle, while Chi 5's impulse is only weakly biased against the others
+	// in this traffic, so it is the one wheel that may not be recovered.
+	for idx := 0; idx < 4; idx++ {
+		if !recovered[idx] {
+			t.Errorf("chi wheel %d pattern not recovered from 80000 characters of military text", idx+1)
+		}
+	}
+}
+
+func TestRecoverChiPatternsShortCipher(t *testing.T) {
+	_, err := lorenz.RecoverChiPatterns(make([]byte, 100))
+	if err == nil {
+		t.Errorf("expected error for ciphertext shorter than the rectangle")
+	}
+}
+
+func TestKeystream(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	plain := make([]byte, 200)
+	for i := range plain {
+		plain[i] = byte(i % 32)
+	}
+
+	key := machine.Keystream(len(plain))
+	chi := machine.ChiStream(len(plain))
+	psi := machine.PsiStream(len(plain))
+	combined, _ := lorenz.Add(chi, psi)
+	if !bytes.Equal(key, combined) {
+		t.Errorf("keystream is not chi + psi.\nKey:\t\t%X\nChi + Psi:\t%X", key, combined)
+	}
+
+	expected, _ := lorenz.Add(plain, key)
+	cipher := machine.Encrypt(plain)
+	if !bytes.Equal(cipher, expected) {
+		t.Errorf("ciphertext is not plaintext + key.\nExpected:\t%X\nActual:\t\t%X", expected, cipher)
+	}
+}
+
+func TestMotorStream(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	motor := machine.MotorStream(100)
+	psi := machine.PsiStream(100)
+	for i := 0; i < len(motor)-1; i++ {
+		// The psi wheels stand still whenever the second motor wheel shows a dot.
+		if motor[i]&1 == 0 && psi[i] != psi[i+1] {
+			t.Errorf("psi wheels moved at character %d with the motor at a dot", i)
+		}
+	}
+}
+
+func TestDeltaAndDeChi(t *testing.T) {
+	d := lorenz.Delta([]byte{0x01, 0x03, 0x03, 0x1f})
+	if !bytes.Equal(d, []byte{0x02, 0x00, 0x1c}) {
+		t.Errorf("incorrect delta %X", d)
+	}
+
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	plain := make([]byte, 50)
+	dechiExpected, _ := lorenz.Add(plain, machine.PsiStream(len(plain)))
+	cipher := machine.Encrypt(plain)
+	machine.ResetRotorPos()
+	if dechi := machine.DeChi(cipher); !bytes.Equal(dechi, dechiExpected) {
+		t.Errorf("de-chi of ciphertext is not plaintext + psi.\nExpected:\t%X\nActual:\t\t%X", dechiExpected, dechi)
+	}
+
+	if _, err := lorenz.Add([]byte{1}, []byte{1, 2}); err == nil {
+		t.Errorf("expected error adding streams of different lengths")
+	}
+}
+
+func TestPackedLorenzMatchesLorenz(t *testing.T) {
+	plain := make([]byte, 2000)
+	for i := range plain {
+		plain[i] = byte((i*7 + i/3) % 32)
+	}
+	starts := [][3][5]byte{
+		{{0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0}},
+		{{1, 2, 3, 4, 5}, {6, 7, 8, 9, 10}, {11, 12}},
+		{{40, 30, 28, 25, 22}, {42, 46, 50, 52, 58}, {60, 36}},
+	}
+	for _, start := range starts {
+		wheels := lorenz.NewWheelSet()
+		machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+		machine.SetChiPos(start[0])
+		machine.SetPsiPos(start[1])
+		machine.SetMotorPos([2]byte{start[2][0], start[2][1]})
+
+		packed := machine.Packed()
+		expected := machine.Encrypt(plain)
+		actual := packed.Encrypt(plain)
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("packed machine differs from Lorenz for start %v", start)
+		}
+	}
+}
+
+func BenchmarkLorenzEncrypt(b *testing.B) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	plain := make([]byte, 10000)
+	b.SetBytes(int64(len(plain)))
+	for i := 0; i < b.N; i++ {
+		machine.Encrypt(plain)
+	}
+}
+
+func BenchmarkPackedLorenzEncrypt(b *testing.B) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewPackedLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	plain := make([]byte, 10000)
+	b.SetBytes(int64(len(plain)))
+	for i := 0; i < b.N; i++ {
+		machine.XORKeyStream(plain, plain)
+	}
+}
+
+// testLetterLists are letter lists giving each wheel the letters A-Z, without J, on its first positions in order.
+const testLetterLists = `
+psi1 ABCDEFGHIKLMNOPQRSTUVWXYZ------------------
+psi2 ABCDEFGHIKLMNOPQRSTUVWXYZ----------------------
+psi3 ABCDEFGHIKLMNOPQRSTUVWXYZ--------------------------
+psi4 ABCDEFGHIKLMNOPQRSTUVWXYZ----------------------------
+psi5 ABCDEFGHIKLMNOPQRSTUVWXYZ----------------------------------
+motor1 ABCDEFGHIKLMNOPQRSTUVWXYZ------------------------------------
+motor2 ABCDEFGHIKLMNOPQRSTUVWXYZ------------
+chi1 ABCDEFGHIKLMNOPQRSTUVWXYZ----------------
+chi2 ABCDEFGHIKLMNOPQRSTUVWXYZ------
+chi3 ABCDEFGHIKLMNOPQRSTUVWXYZ----
+chi4 ABCDEFGHIKLMNOPQRSTUVWXYZ-
+chi5 ABCDEFGHIKLMNOPQRSTUVWX
+`
+
+func TestIndicatorRoundTrip(t *testing.T) {
+	lists, err := lorenz.ParseLetterLists(testLetterLists)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	indicator := "HQIBPEXEZMUG"
+	start, err := lists.Lookup(indicator)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := lorenz.Start{Psi: [5]byte{7, 15, 8, 1, 14}, Motor: [2]byte{4, 22}, Chi: [5]byte{4, 24, 11, 19, 6}}
+	if start != expected {
+		t.Errorf("%v != %v", start, expected)
+	}
+	encoded, err := lists.Indicator(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if encoded != indicator {
+		t.Errorf("%s != %s", encoded, indicator)
+	}
+
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	machine.SetStart(start)
+	if machine.GetStart() != start {
+		t.Errorf("machine start %v != %v", machine.GetStart(), start)
+	}
+}
+
+func TestIndicatorInvalid(t *testing.T) {
+	lists, err := lorenz.ParseLetterLists(testLetterLists)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// J is not used in letter lists and Z is not on the letter list of Chi 5.
+	for _, indicator := range []string{"HQIBPEXEZMU", "JQIBPEXEZMUG", "HQIBPEXEZMUZ"} {
+		if _, err := lists.Lookup(indicator); err == nil {
+			t.Errorf("expected error decoding %s", indicator)
+		}
+	}
+	if _, err := lists.Indicator(lorenz.Start{Psi: [5]byte{30}}); err == nil {
+		t.Errorf("expected error encoding position 30 of Psi 1, which has no letter")
+	}
+}
+
+func TestLetterListsInvalid(t *testing.T) {
+	valid := strings.TrimSpace(testLetterLists)
+	for name, text := range map[string]string{
+		"missing wheel": valid[:strings.LastIndex(valid, "\n")],
+		"short list":    strings.Replace(valid, "chi5 ABCDEFGHIKLMNOPQRSTUVWX", "chi5 ABCDEFGHIKLMNOPQRSTUVW", 1),
+		"repeated":      strings.Replace(valid, "chi5 ABCDEFGHIKLMNOPQRSTUVWX", "chi5 ABCDEFGHIKLMNOPQRSTUVWA", 1),
+		"letter J":      strings.Replace(valid, "chi5 ABCDEFGHIKLMNOPQRSTUVWX", "chi5 ABCDEFGHIKLMNOPQRSTUVWJ", 1),
+		"unknown wheel": strings.Replace(valid, "chi5", "chi6", 1),
+	} {
+		if _, err := lorenz.ParseLetterLists(text); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestGenerateLetterLists(t *testing.T) {
+	lists, err := lorenz.GenerateLetterLists()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	parsed, err := lorenz.ParseLetterLists(lists.Format())
+	if err != nil {
+		t.Fatalf("generated letter lists do not parse: %s", err)
+	}
+	if parsed.Format() != lists.Format() {
+		t.Errorf("letter lists do not survive formatting")
+	}
+	indicator := "HQIBPEXEZMUG"
+	start, err := parsed.Lookup(indicator)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if encoded, _ := parsed.Indicator(start); encoded != indicator {
+		t.Errorf("%s != %s", encoded, indicator)
+	}
+}
+
+func TestIsQEPIndicator(t *testing.T) {
+	for indicator, expected := range map[string]bool{
+		"QEP 17":       true,
+		"qep05":        true,
+		" QEP 99 ":     true,
+		"QEPBPEXEZMUG": false,
+		"HQIBPEXEZMUG": false,
+		"QEP":          false,
+	} {
+		if lorenz.IsQEPIndicator(indicator) != expected {
+			t.Errorf("IsQEPIndicator(%q) != %t", indicator, expected)
+		}
+	}
+
+	// A 12 letter indicator starting with Q, E, P is decoded as letters, not looked up as a QEP number.
+	lists, err := lorenz.ParseLetterLists(testLetterLists)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	start, err := lists.Lookup("QEPBPEXEZMUG")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if encoded, _ := lists.Indicator(start); encoded != "QEPBPEXEZMUG" {
+		t.Errorf("%s != QEPBPEXEZMUG", encoded)
+	}
+}
+
+func TestQEPBook(t *testing.T) {
+	book, err := lorenz.GenerateQEPBook()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	parsed, err := lorenz.ParseQEPBook(book.Format())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, indicator := range []string{"QEP 00", "QEP17", "99"} {
+		expected, _ := book.Lookup(indicator)
+		actual, err := parsed.Lookup(indicator)
+		if err != nil || actual != expected {
+			t.Errorf("%s does not survive formatting the book. Expected: %v, Actual: %v", indicator, expected, actual)
+		}
+	}
+	indicator, err := book.Indicator(book[42])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if start, _ := book.Lookup(indicator); start != book[42] {
+		t.Errorf("%s does not give the start of QEP 42", indicator)
+	}
+	if _, err := book.Lookup("QEP 100"); err == nil {
+		t.Errorf("expected error for QEP number outside the book")
+	}
+}
+
+func TestValidateStandardWheelSet(t *testing.T) {
+	for _, violation := range lorenz.ValidateWheelSet(lorenz.NewWheelSet()) {
+		t.Errorf("standard wheel set should follow the rules: %s", violation.Error())
+	}
+}
+
+func TestValidateWheelSetViolations(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	pins := make([]bool, 41)
+	for i := 0; i < 20; i++ {
+		pins[i] = true
+	}
+	wheels.Chi[0] = lorenz.NewWheel(pins, 0)
+	wheels.Motor[1] = lorenz.NewWheel(make([]bool, 37), 0)
+
+	rules := map[string]bool{}
+	for _, violation := range lorenz.ValidateWheelSet(wheels) {
+		rules[violation.Wheel+": "+violation.Rule] = true
+	}
+	for _, expected := range []string{"Chi 1: limited runs", "Chi 1: balanced delta", "Motor 2: mostly crosses", "Motor 2: single dots"} {
+		if !rules[expected] {
+			t.Errorf("expected violation %s, found %v", expected, rules)
+		}
+	}
+	if rules["Chi 1: half crosses"] {
+		t.Errorf("Chi 1 has 20 of 41 crosses, which is half")
+	}
+}
+
+func TestGenerateWheelSet(t *testing.T) {
+	wheels, err := lorenz.GenerateWheelSet()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, violation := range lorenz.ValidateWheelSet(wheels) {
+		t.Errorf("generated wheel set breaks a rule: %s", violation.Error())
+	}
+
+	parsed, err := lorenz.ParseWheelSet(wheels.Format())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if parsed.Format() != wheels.Format() {
+		t.Errorf("wheel set does not survive formatting.\nExpected:\n%s\nActual:\n%s", wheels.Format(), parsed.Format())
+	}
+}
+
+func TestParseWheelSetMissingWheel(t *testing.T) {
+	text := strings.Join(strings.Split(lorenz.NewWheelSet().Format(), "\n")[1:], "\n")
+	if _, err := lorenz.ParseWheelSet(text); err == nil {
+		t.Errorf("expected error for missing chi1")
+	}
+}
+
+func TestEncryptTrace(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	plain := []byte{0x00, 0x1f, 0x04, 0x10, 0x01, 0x18, 0x03, 0x0e}
+	expected := machine.Encrypt(plain)
+	machine.ResetRotorPos()
+
+	cipher, trace := machine.EncryptTrace(plain)
+	if !bytes.Equal(cipher, expected) {
+		t.Errorf("traced ciphertext differs from Encrypt.\nExpected:\t%X\nActual:\t\t%X", expected, cipher)
+	}
+	for idx, step := range trace {
+		if step.Key != step.Chi^step.Psi || step.Cipher != step.Plain^step.Key {
+			t.Errorf("step %d key or ciphertext is inconsistent: %+v", idx, step)
+		}
+		if idx+1 < len(trace) {
+			moved := trace[idx+1].Positions.Psi != step.Positions.Psi
+			if moved != step.PsiMoved {
+				t.Errorf("step %d reports psi moved as %t but positions show %t", idx, step.PsiMoved, moved)
+			}
+		}
+	}
+
+	table := lorenz.FormatTrace(trace)
+	if rows := strings.Count(table, "\n"); rows != len(plain)+1 {
+		t.Errorf("expected %d rows in trace table, found %d", len(plain)+1, rows)
+	}
+}
+
+func TestDotCross(t *testing.T) {
+	if notation := lorenz.DotCross(0x1a, 5); notation != "xx.x." {
+		t.Errorf("%s != xx.x.", notation)
+	}
+}
+
+func TestLorenzSeek(t *testing.T) {
+	for _, n := range []int{0, 1, 60, 2257, 5000, 100000} {
+		wheels := lorenz.NewWheelSet()
+		stepped := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+		stepped.SetStart(lorenz.Start{Chi: [5]byte{3, 1, 4, 1, 5}, Motor: [2]byte{9, 2}, Psi: [5]byte{6, 5, 3, 5, 8}})
+		sought := stepped
+		stepped.Encrypt(make([]byte, n))
+		sought.Seek(n)
+		if sought.GetStart() != stepped.GetStart() {
+			t.Errorf("Seek(%d) gives %v, stepping gives %v", n, sought.GetStart(), stepped.GetStart())
+		}
+	}
+}
+
+func TestLorenzStepBack(t *testing.T) {
+	wheels := lorenz.NewWheelSet()
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	start := lorenz.Start{Chi: [5]byte{3, 1, 4, 1, 5}, Motor: [2]byte{9, 2}, Psi: [5]byte{6, 5, 3, 5, 8}}
+	machine.SetStart(start)
+	machine.Seek(3000)
+	for i := 0; i < 3000; i++ {
+		machine.StepBack()
+	}
+	if machine.GetStart() != start {
+		t.Errorf("stepping back gives %v, expected %v", machine.GetStart(), start)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	alphabet := lorenz.NewITA2LSB()
+	encoder := lorenz.NewEncoder(alphabet)
+	codes, err := lorenz.EncodeFormat("text", "attack at 0600", &encoder)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, format := range lorenz.FormatNames() {
+		decoder := lorenz.NewDecoder(alphabet)
+		written, err := lorenz.DecodeFormat(format, codes, &decoder)
+		if err != nil {
+			t.Fatalf("unexpected error writing %s: %s", format, err)
+		}
+		encoder := lorenz.NewEncoder(alphabet)
+		read, err := lorenz.EncodeFormat(format, written, &encoder)
+		if err != nil {
+			t.Fatalf("unexpected error reading %s: %s", format, err)
+		}
+		if format != "text" && !bytes.Equal(read, codes) {
+			t.Errorf("%s does not keep the codes. Expected: %v, Actual: %v", format, codes, read)
+		}
+		if format == "text" && written != "ATTACK AT 0600" {
+			t.Errorf("%s != ATTACK AT 0600", written)
+		}
+	}
+	if _, err := lorenz.EncodeFormat("morse", "hello", &encoder); err == nil {
+		t.Errorf("expected error for an unknown format")
+	}
+}
+
+func TestTextCiphertextRoundTrip(t *testing.T) {
+	alphabet := lorenz.NewITA2LSB()
+	wheels := lorenz.NewWheelSet()
+	encoder := lorenz.NewEncoder(alphabet)
+	plain, err := lorenz.EncodeFormat("text", "attack at 0600", &encoder)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	machine := lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	decoder := lorenz.NewDecoder(alphabet)
+	decoder.ShowShifts = true
+	cipherText, err := lorenz.DecodeFormat("text", machine.Encrypt(plain), &decoder)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Ciphertext written with its shift stand-ins is read back to the same codes without being told it is ciphertext.
+	encoder = lorenz.NewEncoder(alphabet)
+	cipher, err := lorenz.EncodeFormat("text", cipherText, &encoder)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	machine = lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi)
+	if decrypted := machine.Encrypt(cipher); !bytes.Equal(decrypted, plain) {
+		t.Errorf("%v != %v", decrypted, plain)
+	}
+
+	if lorenz.OutputFormat("text") != "notation" || lorenz.OutputFormat("notation") != "text" {
+		t.Errorf("text should be written as notation and codes as text")
+	}
+}
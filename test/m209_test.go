@@ -0,0 +1,110 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/m209"
+	"strings"
+	"testing"
+)
+
+// m209KeyList is the example key list from the M-209 technical manual, TM 11-380.
+const m209KeyList = `# example key list
+lugs 3-6 0-6 1-6 1-5 4-5 0-4 0-4 0-4 0-4 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-5 2-5 0-5 0-5 0-5 0-5 0-5 0-5
+wheel1 ABDHIKMNSTVW
+wheel2 ADEGJKLORSUX
+wheel3 ABGHJLMNRSTUX
+wheel4 CEFHIMNPSTU
+wheel5 BDEFHIMNPS
+wheel6 ABDHKNOQ
+`
+
+func TestM209ManualCheck(t *testing.T) {
+	// TM 11-380 checks a machine by setting the example key list, turning every wheel to A and enciphering 26 As.
+	// Every letter depends on the offset of the pin sensed on each wheel and on the direction of the Beaufort cipher.
+	settings, err := m209.ParseSettings(m209KeyList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine, _ := m209.NewM209(settings)
+	if err := machine.SetPositions("AAAAAA"); err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := machine.Encrypt(strings.Repeat("A", 26))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cipher != "TNJUWAUQTKCZKNUTOTBCWARMIO" {
+		t.Errorf("Expected the manual's check TNJUW AUQTK CZKNU TOTBC WARMI O, got %s", cipher)
+	}
+}
+
+func TestM209NoActivePins(t *testing.T) {
+	settings := m209.Settings{}
+	for i := range settings.Pins {
+		settings.Pins[i] = make([]bool, len(m209.WheelLetters(i+1)))
+	}
+	machine, err := m209.NewM209(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With no key the Beaufort cipher maps each letter to its mirror image.
+	cipher, _ := machine.Encrypt("ABC XYZ")
+	if cipher != "ZYXACBA" {
+		t.Errorf("Expected ZYXACBA with no active pins, got %s", cipher)
+	}
+}
+
+func TestM209RoundTrip(t *testing.T) {
+	settings, err := m209.ParseSettings(m209KeyList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine, _ := m209.NewM209(settings)
+	if err := machine.SetPositions("QWERTY"); err == nil {
+		t.Errorf("expected error for letter W on wheel 2")
+	}
+	if err := machine.SetPositions("GABLEQ"); err != nil {
+		t.Fatal(err)
+	}
+	plaintext := "ATTACK AT DAWN"
+	cipher, err := machine.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine.GetPositions() != "UOPESN" {
+		t.Errorf("Expected every wheel to step once per letter, got %s", machine.GetPositions())
+	}
+
+	if err := machine.SetPositions("GABLEQ"); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := machine.Decrypt(cipher[:5] + " " + cipher[5:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != plaintext {
+		t.Errorf("Expected %s, got %s", plaintext, plain)
+	}
+}
+
+func TestM209Settings(t *testing.T) {
+	settings, err := m209.ParseSettings(m209KeyList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := m209.ParseSettings(settings.Format())
+	if err != nil || again.Format() != settings.Format() {
+		t.Errorf("Settings did not survive formatting:\n%s", settings.Format())
+	}
+
+	broken := []string{
+		strings.Replace(m209KeyList, "wheel6 ABDHKNOQ", "wheel6 ABDHKNOQZ", 1),
+		strings.Replace(m209KeyList, "wheel6 ABDHKNOQ\n", "", 1),
+		strings.Replace(m209KeyList, "3-6", "6-6", 1),
+		strings.Replace(m209KeyList, "3-6", "3-7", 1),
+	}
+	for _, text := range broken {
+		if _, err := m209.ParseSettings(text); err == nil {
+			t.Errorf("expected error parsing settings:\n%s", text)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/fialka"
+	"EnigmaLorenz/pkg/hebern"
+	"EnigmaLorenz/pkg/lorenz"
+	"EnigmaLorenz/pkg/machine"
+	"testing"
+)
+
+// roundTrip encrypts and decrypts plaintext through a Machine, checking state save, restore and reset along the way.
+func roundTrip(t *testing.T, m machine.Machine, plaintext string) {
+	start := m.State()
+	cipher, err := m.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("%s: %s", m.Name(), err)
+	}
+	if err := m.Restore(start); err != nil {
+		t.Fatalf("%s: %s", m.Name(), err)
+	}
+	plain, err := m.Decrypt(cipher)
+	if err != nil {
+		t.Fatalf("%s: %s", m.Name(), err)
+	}
+	if plain != plaintext {
+		t.Errorf("%s: expected %q after restore, got %q", m.Name(), plaintext, plain)
+	}
+
+	m.Reset()
+	again, _ := m.Encrypt(plaintext)
+	if again != cipher {
+		t.Errorf("%s: expected %q after reset, got %q", m.Name(), cipher, again)
+	}
+}
+
+// reflects returns whether no symbol of plaintext is enciphered to itself, as on machines with a reflector.
+func reflects(plaintext string, cipher string) bool {
+	plain, enciphered := []rune(plaintext), []rune(cipher)
+	for idx := range plain {
+		if idx < len(enciphered) && plain[idx] == enciphered[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMachines(t *testing.T) {
+	// Enigma I with rotors I-II-III, UKW-B, ring settings AAA and start position AAA.
+	rotorSet := enigma.GenerateRotors()
+	e := enigma.Enigma{
+		LeftRotor:   rotorSet.I,
+		CenterRotor: rotorSet.II,
+		RightRotor:  rotorSet.III,
+		Reflector:   rotorSet.UKW_B,
+	}
+	wheels := lorenz.NewWheelSet()
+
+	// The Enigma rows are the widely published test vectors for the Enigma I setting above.
+	// Every other row is a regression golden recorded from this code: it catches changes in behaviour,
+	// but does not show that the machine matches the original.
+	tests := []struct {
+		machine   machine.Machine
+		plaintext string
+		golden    string
+		reflector bool
+	}{
+		{machine.NewEnigma(e, false), "AAAAA", "BDZGO", true},
+		{machine.NewEnigma(e, false), "HELLOWORLD", "ILBDAAMTAZ", true},
+		{machine.NewLorenz(lorenz.NewLorenz(wheels.Chi, wheels.Motor, wheels.Psi), lorenz.NewITA2LSB()), "ATTACK AT 0600.", "OB53H49JR/DXT3RR", false},
+		{machine.NewTypex(newTestTypex()), "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", "KNZXNDJCMBFXRYNDCXDINTGITFGQOXVZVIU", true},
+		{machine.NewSIGABA(newTestSIGABA()), "ATTACK AT DAWN", "WIOAWBBNZULROK", false},
+		{machine.NewFialka(newTestFialka(fialka.LatinKeyboard())), "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG.", "RSVAWDIO/IKM/CENVA.VHWBOO-,ZOWMXMXIW", true},
+		{machine.NewFialka(newTestFialka(fialka.CyrillicKeyboard())), "ПРИВЕТМИР", "ЯЫЩШВБСЯЩ", true},
+		{machine.NewNEMA(newTestNEMA()), "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", "LXQVVCWJEILYLMTNGRSJRYLSEWTLIXQBLLJ", true},
+		{machine.NewHebern(newTestHebern()), "THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", "RFQWHYJCZGEMHNMHIGJUFIKYNMZJDIHFEJY", false},
+		{machine.NewHebern(hebern.NewSingleRotor(rotorSet.III)), "ATTACKATDAWN", "BJKEYZWUNKSF", false},
+	}
+	for _, test := range tests {
+		cipher, err := test.machine.Encrypt(test.plaintext)
+		if err != nil {
+			t.Fatalf("%s: %s", test.machine.Name(), err)
+		}
+		if cipher != test.golden {
+			t.Errorf("%s: expected %q, got %q", test.machine.Name(), test.golden, cipher)
+		}
+		if test.reflector && !reflects(test.plaintext, cipher) {
+			t.Errorf("%s: a symbol of %q was enciphered to itself in %q", test.machine.Name(), test.plaintext, cipher)
+		}
+		test.machine.Reset()
+		roundTrip(t, test.machine, test.plaintext)
+	}
+
+	if err := machine.NewEnigma(e, false).Restore(lorenz.Start{}); err == nil {
+		t.Errorf("expected error restoring a Lorenz state to an Enigma")
+	}
+}
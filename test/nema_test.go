@@ -0,0 +1,103 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/nema"
+	"strings"
+	"testing"
+)
+
+func newTestNEMA() nema.NEMA {
+	set := nema.TrainingWheels()
+	m := nema.NEMA{UKW: set.UKW, DriveWheel: set.RedWheels[4]}
+	for idx := 0; idx < 4; idx++ {
+		m.ContactWheels[idx] = set.ContactWheels[idx]
+		m.RedWheels[idx] = set.RedWheels[idx]
+	}
+	return m
+}
+
+func TestNEMAStepping(t *testing.T) {
+	m := newTestNEMA()
+	// A red wheel with a single notch steps its contact wheel once per turn.
+	m.RedWheels[3].Notches = []byte{0}
+	m.DriveWheel.Notches = []byte{}
+	_, _ = m.Encrypt("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	if m.ContactWheels[3].GetShownPos() != 1+4 {
+		t.Errorf("Contact wheel should step once for each of 4 notch passes in 80 keys, at %d", m.ContactWheels[3].GetShownPos())
+	}
+	if m.UKW.GetShownPos() != 1 {
+		t.Errorf("UKW should not step without drive wheel notches")
+	}
+	if m.DriveWheel.GetPos() != 80%26 {
+		t.Errorf("Drive wheel should turn with every key, at %d", m.DriveWheel.GetPos())
+	}
+}
+
+func TestNEMAWheelSets(t *testing.T) {
+	operational, err := nema.NewWheelSet("operational")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := operational.ContactWheel("11"); err != nil {
+		t.Errorf("operational set should have contact wheel 11")
+	}
+	if _, err := operational.RedWheel("1"); err == nil {
+		t.Errorf("operational set should not have red wheel 1")
+	}
+	if _, err := nema.NewWheelSet("spare"); err == nil {
+		t.Errorf("expected error for unknown wheel set")
+	}
+	for _, wheel := range append(nema.TrainingWheels().ContactWheels, operational.ContactWheels...) {
+		seen := [26]bool{}
+		for _, wire := range wheel.Wires {
+			seen[wire] = true
+		}
+		for letter, found := range seen {
+			if !found {
+				t.Errorf("Contact wheel %s does not wire anything to %c", wheel.Name, 'A'+letter)
+			}
+		}
+	}
+}
+
+func TestNEMAParseWheelSet(t *testing.T) {
+	catalog := `rotors:
+  - name: "1"
+    wiring: EKMFLGDQVZNTOWYHXUSPAIBRCJ
+  - name: "2"
+    wiring: ABCDEFGHIJKLMNOPQRSTUVWXYZ
+    notches: AM
+  - name: UKW
+    wiring: YRUHQSLDPXNGOKMIEBFZCWVJAT
+    reflector: true
+`
+	set, err := nema.ParseWheelSet("custom", catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.ContactWheels) != 1 || set.ContactWheels[0].Name != "1" {
+		t.Errorf("Expected contact wheel 1, got %v", set.ContactWheels)
+	}
+	if red, err := set.RedWheel("2"); err != nil || string(red.Notches) != string([]byte{0, 12}) {
+		t.Errorf("Expected red wheel 2 with notches at A and M, got %v", red.Notches)
+	}
+	if set.UKW.Name != "UKW" {
+		t.Errorf("Expected reflector UKW, got %s", set.UKW.Name)
+	}
+
+	noReflector := strings.Split(catalog, "  - name: UKW")[0]
+	if _, err := nema.ParseWheelSet("custom", noReflector); err == nil {
+		t.Errorf("expected error for a wheel set without a reflector")
+	}
+	digits := `rotors:
+  - name: "1"
+    wiring: "1234567890"
+    alphabet: "0123456789"
+  - name: UKW
+    wiring: YRUHQSLDPXNGOKMIEBFZCWVJAT
+    reflector: true
+`
+	if _, err := nema.ParseWheelSet("custom", digits); err == nil {
+		t.Errorf("expected error for a wheel without 26 contacts")
+	}
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/sigaba"
+	"testing"
+)
+
+func newTestSIGABA() sigaba.SIGABA {
+	rotorSet := sigaba.GenerateRotors()
+	m := sigaba.SIGABA{}
+	for i := 0; i < 5; i++ {
+		m.CipherRotors[i] = rotorSet.Rotors[i]
+		m.ControlRotors[i] = rotorSet.Rotors[i+5]
+		m.IndexRotors[i] = rotorSet.IndexRotors[i]
+	}
+	m.CipherRotors[1] = m.CipherRotors[1].Reversed()
+	return m
+}
+
+func TestSIGABARotorsArePermutations(t *testing.T) {
+	rotorSet := sigaba.GenerateRotors()
+	for _, rotor := range append(rotorSet.Rotors[:], rotorSet.IndexRotors[:]...) {
+		seen := make([]bool, len(rotor.Wires))
+		for _, wire := range rotor.Wires {
+			seen[wire] = true
+		}
+		for contact, found := range seen {
+			if !found {
+				t.Errorf("Rotor %s does not wire anything to contact %d", rotor.Name, contact)
+			}
+		}
+	}
+}
+
+func TestSIGABAAlphabet(t *testing.T) {
+	encrypting := newTestSIGABA()
+	cipher, err := encrypting.Encrypt("ZULU TIME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypting := newTestSIGABA()
+	if plain, _ := decrypting.Decrypt(cipher); plain != "XULU TIME" {
+		t.Errorf("Expected XULU TIME with Z typed as X, got %s", plain)
+	}
+	if _, err := encrypting.Encrypt("ZULU 1"); err == nil {
+		t.Errorf("expected error for a digit in the plaintext")
+	}
+}
+
+func TestSIGABAStepping(t *testing.T) {
+	m := newTestSIGABA()
+	previous := m.CipherRotors
+	for i := 0; i < 200; i++ {
+		_, _ = m.Encrypt("A")
+		stepped := 0
+		for idx := range previous {
+			if previous[idx].GetShownPos() != m.CipherRotors[idx].GetShownPos() {
+				stepped++
+			}
+		}
+		if stepped < 1 || stepped > 4 {
+			t.Fatalf("Between 1 and 4 cipher rotors should step with each letter, %d stepped at letter %d", stepped, i)
+		}
+		previous = m.CipherRotors
+	}
+
+	// The center control rotor steps with every letter, carrying the fourth on as it passes O.
+	if m.ControlRotors[2].GetShownPos() != 1+200%26 || m.ControlRotors[3].GetShownPos() != 1+8 {
+		t.Errorf("Control rotors at %d and %d after 200 letters", m.ControlRotors[2].GetShownPos(), m.ControlRotors[3].GetShownPos())
+	}
+	for _, rotor := range []enigma.Rotor{m.ControlRotors[0], m.ControlRotors[4], m.IndexRotors[0]} {
+		if rotor.GetShownPos() != 1 {
+			t.Errorf("Rotor %s should never step", rotor.Name)
+		}
+	}
+}
+
+func TestSIGABAReversedStepping(t *testing.T) {
+	m := newTestSIGABA()
+	m.ControlRotors[2] = m.ControlRotors[2].Reversed()
+	moved := 0
+	for i := 0; i < 100; i++ {
+		before := m.CipherRotors[1].GetShownPos()
+		_, _ = m.Encrypt("A")
+		after := m.CipherRotors[1].GetShownPos()
+		if after == before {
+			continue
+		}
+		moved++
+		if int(after) != (int(before)+24)%26+1 {
+			t.Fatalf("Reversed cipher rotor should step backwards, went from %d to %d", before, after)
+		}
+	}
+	if moved == 0 {
+		t.Errorf("Reversed cipher rotor never stepped")
+	}
+
+	// The reversed center control rotor counts down from A with every letter, carrying the fourth on as it passes O.
+	if m.ControlRotors[2].GetShownPos() != 1+(26-100%26)%26 || m.ControlRotors[3].GetShownPos() != 1+4 {
+		t.Errorf("Control rotors at %d and %d after 100 letters", m.ControlRotors[2].GetShownPos(), m.ControlRotors[3].GetShownPos())
+	}
+	if twice := m.CipherRotors[1].Reversed(); twice.IsReversed() {
+		t.Errorf("A rotor reversed twice should step forwards")
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/tape"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTapeDump(t *testing.T) {
+	dump := tape.Dump([]byte{0x18, 0x01, 0x1f})
+	expected := "xx...\n....x\nxxxxx\n"
+	if dump != expected {
+		t.Errorf("%q != %q", dump, expected)
+	}
+}
+
+func TestTapeParseRoundTrip(t *testing.T) {
+	codes := make([]byte, 32)
+	for i := range codes {
+		codes[i] = byte(i)
+	}
+	for _, rendered := range []string{tape.Dump(codes), tape.RenderASCII(codes)} {
+		parsed, err := tape.Parse(rendered)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !bytes.Equal(parsed, codes) {
+			t.Errorf("%x != %x", parsed, codes)
+		}
+	}
+}
+
+func TestTapeParseSprocket(t *testing.T) {
+	parsed, err := tape.Parse("xxo...\n..o..x")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(parsed, []byte{0x18, 0x01}) {
+		t.Errorf("%x != 1801", parsed)
+	}
+}
+
+func TestTapeParseInvalid(t *testing.T) {
+	for _, dump := range []string{"xx..", "xx..-", "xx.x.x.x"} {
+		if _, err := tape.Parse(dump); err == nil {
+			t.Errorf("expected error parsing %q", dump)
+		}
+	}
+}
+
+func TestTapeRenderSVG(t *testing.T) {
+	svg := tape.RenderSVG([]byte{0x1f, 0x00})
+	// 5 holes and a sprocket hole for the first row, only a sprocket hole for the second.
+	if count := strings.Count(svg, "<circle"); count != 7 {
+		t.Errorf("expected 7 holes in SVG, found %d", count)
+	}
+}
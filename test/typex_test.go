@@ -0,0 +1,145 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/enigma"
+	"EnigmaLorenz/pkg/typex"
+	"bytes"
+	"testing"
+)
+
+func newTestTypex() typex.Typex {
+	rotorSet := typex.GenerateRotors()
+	entry, _ := typex.NewEntry("")
+	return typex.Typex{
+		LeftRotor:   rotorSet.A,
+		CenterRotor: typex.Reverse(rotorSet.B),
+		RightRotor:  rotorSet.C,
+		LeftStator:  rotorSet.D,
+		RightStator: rotorSet.E,
+		Reflector:   rotorSet.Reflector,
+		Entry:       entry,
+	}
+}
+
+func TestTypexRotorsArePermutations(t *testing.T) {
+	rotorSet := typex.GenerateRotors()
+	for _, rotor := range []enigma.Rotor{rotorSet.A, rotorSet.B, rotorSet.C, rotorSet.D, rotorSet.E, rotorSet.F, rotorSet.G, rotorSet.H, rotorSet.Reflector} {
+		seen := [26]bool{}
+		for _, wire := range rotor.Wires {
+			seen[wire] = true
+		}
+		for letter, found := range seen {
+			if !found {
+				t.Errorf("Rotor %s does not wire anything to %c", rotor.Name, 'A'+letter)
+			}
+		}
+	}
+}
+
+func TestTypexReverse(t *testing.T) {
+	rotor := typex.GenerateRotors().A
+	reversed := typex.Reverse(rotor)
+	if reversed.Name != "AR" || bytes.Equal(reversed.Wires, rotor.Wires) {
+		t.Errorf("Reversed rotor %s has the same wiring as the original", reversed.Name)
+	}
+	if twice := typex.Reverse(reversed); !bytes.Equal(twice.Wires, rotor.Wires) {
+		t.Errorf("Reversing a rotor twice should restore its wiring.\nExpected:\t%v\nActual:\t\t%v", rotor.Wires, twice.Wires)
+	}
+}
+
+func TestTypexStepping(t *testing.T) {
+	m := newTestTypex()
+	// Without notches on the center rotor, only the right rotor's notches move it.
+	m.CenterRotor.TurnoverList = nil
+	_, _ = m.Encrypt("AAAAAAAAAAAAAAAAAAAAAAAAAA")
+	// The right rotor C passes each of its 9 notches once in a full turn, stepping the center rotor each time.
+	if m.RightRotor.GetShownPos() != 1 || m.CenterRotor.GetShownPos() != 10 {
+		t.Errorf("Expected right rotor at 1 and center at 10 after 26 letters, got %d and %d",
+			m.RightRotor.GetShownPos(), m.CenterRotor.GetShownPos())
+	}
+	if m.LeftStator.GetShownPos() != 1 || m.RightStator.GetShownPos() != 1 {
+		t.Errorf("Stators should never move")
+	}
+}
+
+func TestTypexNotches(t *testing.T) {
+	rotorSet := typex.GenerateRotors()
+	rotors := []enigma.Rotor{rotorSet.A, rotorSet.B, rotorSet.C, rotorSet.D, rotorSet.E, rotorSet.F, rotorSet.G, rotorSet.H}
+	rings := make(map[string]string)
+	for _, rotor := range rotors {
+		if other, exists := rings[string(rotor.TurnoverList)]; exists {
+			t.Errorf("Rotors %s and %s have the same notches", other, rotor.Name)
+		}
+		rings[string(rotor.TurnoverList)] = rotor.Name
+
+		// Each rotor in the right slot steps the center rotor once for every notch on its own ring.
+		m := newTestTypex()
+		m.RightRotor = rotor
+		m.CenterRotor.TurnoverList = nil
+		_, _ = m.Encrypt("AAAAAAAAAAAAAAAAAAAAAAAAAA")
+		if int(m.CenterRotor.GetShownPos()) != 1+len(rotor.TurnoverList) {
+			t.Errorf("Rotor %s has %d notches but stepped the center rotor to %d",
+				rotor.Name, len(rotor.TurnoverList), m.CenterRotor.GetShownPos())
+		}
+	}
+
+	// With several notches on the center rotor B, the left rotor steps with every key pressed while the center rotor is at a notch,
+	// which happens more than once in each turn of the center rotor.
+	m := newTestTypex()
+	m.CenterRotor = rotorSet.B
+	atNotch := 0
+	for i := 0; i < 26*3; i++ {
+		if m.CenterRotor.AtNotch() {
+			atNotch++
+		}
+		_, _ = m.Encrypt("A")
+	}
+	if atNotch <= 2 || int(m.LeftRotor.GetShownPos()) != 1+atNotch {
+		t.Errorf("Expected the left rotor at %d after the center rotor was at a notch %d times, got %d",
+			1+atNotch, atNotch, m.LeftRotor.GetShownPos())
+	}
+}
+
+func TestTypexSettings(t *testing.T) {
+	if _, err := typex.NewReflector("AB CD"); err == nil {
+		t.Errorf("expected error for a reflector with too few pairs")
+	}
+	if _, err := typex.NewReflector("AA BC FG IE KD LU MH OR TS VZ WQ XJ YP"); err == nil {
+		t.Errorf("expected error for a letter paired with itself")
+	}
+	if _, err := typex.NewEntry("ABCDEFGHIJKLMNOPQRSTUVWXYY"); err == nil {
+		t.Errorf("expected error for an entry that is not a permutation")
+	}
+}
+
+func TestTypexAlphabet(t *testing.T) {
+	z30 := enigma.GenerateZ30Rotors()
+	entry := enigma.Rotor{Name: "Entry", Wires: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, Alphabet: enigma.DigitAlphabet()}
+	newDigitTypex := func() typex.Typex {
+		return typex.Typex{
+			LeftRotor:   z30.I,
+			CenterRotor: z30.II,
+			RightRotor:  z30.III,
+			LeftStator:  z30.I,
+			RightStator: z30.II,
+			Reflector:   z30.UKW,
+			Entry:       entry,
+		}
+	}
+
+	machine := newDigitTypex()
+	cipher, err := machine.Encrypt("0123456789")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	again := newDigitTypex()
+	if plain, err := again.Encrypt(cipher); err != nil || plain != "0123456789" {
+		t.Errorf("%q != %q (%v)", plain, "0123456789", err)
+	}
+
+	mixed := newTestTypex()
+	mixed.RightRotor = z30.III
+	if _, err := mixed.Encrypt("123"); err == nil {
+		t.Error("expected an error for rotors lettered with different alphabets")
+	}
+}
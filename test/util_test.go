@@ -0,0 +1,27 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/util"
+	"testing"
+)
+
+func TestUtilFormatGroups(t *testing.T) {
+	formatted := util.FormatGroups("ABCDEFGHIJKLMNOPQRSTUVW", 5, 2)
+	expected := "ABCDE FGHIJ\nKLMNO PQRST\nUVW"
+	if formatted != expected {
+		t.Errorf("%q != %q", formatted, expected)
+	}
+	if formatted := util.FormatGroups("ABCDEFGH", 4, 0); formatted != "ABCD EFGH" {
+		t.Errorf("%q != %q", formatted, "ABCD EFGH")
+	}
+	// Symbols outside ASCII are counted whole, so Cyrillic is grouped by letter rather than by byte.
+	if formatted := util.FormatGroups("УПЧОПЭШРХ", 5, 0); formatted != "УПЧОП ЭШРХ" {
+		t.Errorf("%q != %q", formatted, "УПЧОП ЭШРХ")
+	}
+	if formatted := util.FormatGroups("ABCDEFGH", 0, 2); formatted != "ABCDEFGH" {
+		t.Errorf("%q != %q", formatted, "ABCDEFGH")
+	}
+	if groups := util.SplitGroups("", 0); len(groups) != 0 {
+		t.Errorf("Expected no groups for empty text, got %q", groups)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"EnigmaLorenz/pkg/lorenz"
+	"EnigmaLorenz/pkg/tape"
+	"EnigmaLorenz/pkg/vernam"
+	"bytes"
+	"testing"
+)
+
+func TestVernamGenerateKey(t *testing.T) {
+	key, err := vernam.GenerateKey(500)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(key) != 500 {
+		t.Fatalf("key tape has %d codes, expected 500", len(key))
+	}
+	for _, code := range key {
+		if code > 0x1f {
+			t.Fatalf("key code %x is not a 5 bit ITA2 code", code)
+		}
+	}
+}
+
+func TestVernamRoundTrip(t *testing.T) {
+	encoder := lorenz.NewEncoder(lorenz.NewITA2LSB())
+	plain, err := encoder.Encode("ATTACK AT DAWN 0600")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	key, err := vernam.GenerateKey(len(plain) + 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The key tape survives being punched and read back.
+	key, err = tape.Parse(tape.RenderASCII(key))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cipher, err := vernam.Encrypt(plain, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	decrypted, err := vernam.Decrypt(cipher, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(decrypted, plain) {
+		t.Errorf("%x != %x", decrypted, plain)
+	}
+}
+
+func TestVernamKnownCodes(t *testing.T) {
+	cipher, err := vernam.Encrypt([]byte{0x18, 0x01, 0x00}, []byte{0x1f, 0x01, 0x0a, 0x03})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []byte{0x07, 0x00, 0x0a}; !bytes.Equal(cipher, expected) {
+		t.Errorf("%x != %x", cipher, expected)
+	}
+}
+
+func TestVernamShortKey(t *testing.T) {
+	if _, err := vernam.Encrypt([]byte{1, 2, 3}, []byte{1, 2}); err == nil {
+		t.Error("expected an error for a key tape shorter than the message")
+	}
+}